@@ -0,0 +1,266 @@
+package promise
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AllRate starts the factories no faster than rps per second, and resolves with all their results in order like All.
+// The rate must be positive and finite.
+func AllRate(rps float64, factories ...func() Promise) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		if !(rps > 0) || math.IsInf(rps, 1) {
+			return NewPromiseError(fmt.Sprintf("AllRate requires a positive, finite rate, found %v", rps))
+		}
+
+		interval := time.Duration(math.MaxInt64) // rates below one per 292 years
+		if nanos := float64(time.Second) / rps; nanos < math.MaxInt64 {
+			interval = time.Duration(nanos)
+		}
+		if interval < 1 {
+			interval = 1 // rates above one per nanosecond
+		}
+		proms := make([]Promise, len(factories))
+		if len(proms) == 0 {
+			resolve([]Unknown{})
+			return nil
+		}
+
+		// each start schedules the next on the clock installed with SetClock
+		var start func(i int)
+		start = func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					reject(recoveredError(r))
+				}
+			}()
+
+			proms[i] = factories[i]()
+			if i+1 < len(proms) {
+				afterFunc(interval, func() {
+					start(i + 1)
+				})
+			} else {
+				All(proms...).Then(resolve, reject)
+			}
+		}
+
+		start(0)
+		return nil
+	})
+}
+
+// FirstSuccess calls the factories one at a time, in order, moving on to the next only once the current promise
+// rejects. It resolves with the first success, or rejects with a *MultiPromiseError of every failure. A factory
+// that panics rejects it with a *PanicError, without trying the rest.
+func FirstSuccess(factories ...func() Promise) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		tried := make([]Promise, 0, len(factories))
+
+		var attempt func(i int)
+		attempt = func(i int) {
+			if i == len(factories) {
+				reject(NewMultiPromiseError("all factories failed", tried))
+				return
+			}
+			defer func() { // later attempts run in a rejector, which would swallow the panic
+				if r := recover(); r != nil {
+					reject(recoveredError(r))
+				}
+			}()
+			prom := factories[i]()
+			tried = append(tried, prom)
+			prom.Then(resolve, func(error) Unknown {
+				attempt(i + 1)
+				return nil
+			})
+		}
+
+		attempt(0)
+		return nil
+	})
+}
+
+// KeyedError identifies which keyed input caused a rejection.
+type KeyedError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyedError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Err.Error())
+}
+
+func (e *KeyedError) Unwrap() error {
+	return e.Err
+}
+
+// AllKeyedLimit calls the factories with at most limit of their promises pending at once, and resolves with a
+// map of every key to its result. On the first rejection it stops starting factories, and rejects with a
+// *KeyedError naming the failing key. Factories start in no particular order. A factory that panics rejects
+// it with a *PanicError.
+func AllKeyedLimit(m map[string]func() Promise, limit int) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		if limit <= 0 {
+			return NewPromiseError("AllKeyedLimit requires a positive limit")
+		}
+
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var mutex sync.Mutex
+		results := make(map[string]Unknown, len(m))
+		next := 0
+		failed := false
+
+		if len(keys) == 0 {
+			resolve(results)
+			return nil
+		}
+
+		var start func()
+		start = func() {
+			mutex.Lock()
+			if failed || next == len(keys) {
+				mutex.Unlock()
+				return
+			}
+			key := keys[next]
+			next += 1
+			mutex.Unlock()
+
+			defer func() { // later starts run in a resolver, which would swallow the panic
+				if r := recover(); r != nil {
+					mutex.Lock()
+					failed = true
+					mutex.Unlock()
+					reject(recoveredError(r))
+				}
+			}()
+
+			m[key]().Then(func(u Unknown) Unknown {
+				mutex.Lock()
+				results[key] = u
+				done := len(results) == len(keys)
+				mutex.Unlock()
+
+				if done {
+					return resolve(results)
+				}
+				start()
+				return nil
+			}, func(err error) Unknown {
+				mutex.Lock()
+				failed = true
+				mutex.Unlock()
+				return reject(&KeyedError{Key: key, Err: err})
+			})
+		}
+
+		for i := 0; i < limit; i++ {
+			start()
+		}
+		return nil
+	})
+}
+
+// MapThen applies Then(onsuccess, onfail) to each of the promises, returning the derived promises in order,
+// ready to combine with All or Merge.
+func MapThen(onsuccess Resolver, onfail Rejector, proms ...Promise) []Promise {
+	derived := make([]Promise, len(proms))
+	for i, p := range proms {
+		derived[i] = p.Then(onsuccess, onfail)
+	}
+	return derived
+}
+
+type done struct{}
+
+func (done) String() string {
+	return "<done>"
+}
+
+// Done is the value a Pipe source resolves with once it has no more items.
+var Done Unknown = done{}
+
+// Pipe pulls items from source, one pull at a time, and feeds each to sink, with at most concurrency sink
+// promises pending at once; pulling pauses while that many are. It resolves with the number of items moved
+// once source resolves with Done and every sink promise has fulfilled. The first rejection from source or
+// sink rejects the pipe, and stops pulling, as does a panic in either, with a *PanicError.
+func Pipe(source func() Promise, sink func(Unknown) Promise, concurrency int) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		if concurrency <= 0 {
+			return NewPromiseError("Pipe requires a positive concurrency")
+		}
+
+		var mutex sync.Mutex
+		inflight, moved := 0, 0
+		pulling, exhausted, failed := false, false, false
+
+		fail := func(err error) Unknown {
+			mutex.Lock()
+			failed = true
+			mutex.Unlock()
+			return reject(err)
+		}
+
+		var pump func()
+		pump = func() {
+			mutex.Lock()
+			if failed || pulling || exhausted || inflight >= concurrency {
+				finished, count := exhausted && inflight == 0 && !failed, moved
+				mutex.Unlock()
+				if finished {
+					resolve(count)
+				}
+				return
+			}
+			pulling = true
+			mutex.Unlock()
+
+			call(source).Then(func(item Unknown) Unknown {
+				mutex.Lock()
+				pulling = false
+				if item == Done {
+					exhausted = true
+					mutex.Unlock()
+					pump()
+					return nil
+				}
+				inflight += 1
+				mutex.Unlock()
+
+				call(func() Promise { return sink(item) }).Then(func(Unknown) Unknown {
+					mutex.Lock()
+					inflight -= 1
+					moved += 1
+					mutex.Unlock()
+					pump()
+					return nil
+				}, fail)
+				pump()
+				return nil
+			}, fail)
+		}
+
+		pump()
+		return nil
+	})
+}
+
+// call calls factory, converting a panic into a rejected promise, for factories called from callbacks, where
+// the panic would otherwise reject only a derived promise nothing observes.
+func call(factory func() Promise) (prom Promise) {
+	defer func() {
+		if r := recover(); r != nil {
+			prom = Reject(recoveredError(r))
+		}
+	}()
+	return factory()
+}
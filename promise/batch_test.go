@@ -0,0 +1,208 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPromise_AllRate(t *testing.T) {
+	fake := useFakeClock(t)
+	starts := []time.Time{}
+
+	factories := make([]func() Promise, 4)
+	for i := range factories {
+		value := i
+		factories[i] = func() Promise {
+			starts = append(starts, fake.Now())
+			return Resolve(value)
+		}
+	}
+
+	// 4 starts at 20/s are spread over 3 intervals of 50ms
+	prom := AllRate(20, factories...)
+	fake.Advance(149 * time.Millisecond)
+	Assert(t, len(starts) == 3 && prom.GetStatus() == "Pending", "AllRate() started %d factories in 149ms", len(starts))
+	fake.Advance(time.Millisecond)
+
+	res, err := prom.Wait()
+	Assert(t, err == nil, "AllRate() failed with an unexpected error: %s", err)
+	values, _ := (res).([]Unknown)
+	Assert(t, len(values) == len(factories), "AllRate() produced %d results, expected %d", len(values), len(factories))
+	for i, val := range values {
+		Assert(t, val == i, "AllRate() produced a mismatching value: %v != %v", val, i)
+	}
+	Assert(t, starts[3].Sub(starts[0]) == 150*time.Millisecond, "AllRate() spread the starts over %s", starts[3].Sub(starts[0]))
+
+	res, err = AllRate(20).Wait()
+	values, _ = (res).([]Unknown)
+	Assert(t, err == nil && len(values) == 0, "AllRate() without factories did not resolve empty: %v, %v", res, err)
+
+	for _, rps := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		_, err = AllRate(rps, factories...).Wait()
+		Assert(t, err != nil, "AllRate() accepted a rate of %v", rps)
+	}
+
+	prom = AllRate(2e9, factories...)
+	fake.Advance(3 * time.Nanosecond)
+	res, err = prom.Wait()
+	Assert(t, err == nil && len((res).([]Unknown)) == 4, "AllRate() failed above one start per nanosecond: %v, %v", res, err)
+}
+
+func TestPromise_FirstSuccess(t *testing.T) {
+	calls := 0
+	failing := func() Promise {
+		calls += 1
+		return Reject(fmt.Errorf("backend %d down", calls))
+	}
+
+	res, err := FirstSuccess(failing, failing, func() Promise {
+		calls += 1
+		return Resolve("third")
+	}, failing).Wait()
+	Assert(t, err == nil && res == "third", "FirstSuccess() did not resolve with the first success: %v, %v", res, err)
+	Assert(t, calls == 3, "FirstSuccess() called %d factories, expected 3", calls)
+
+	calls = 0
+	_, err = FirstSuccess(failing, failing).Wait()
+	multi, ok := (err).(*MultiPromiseError)
+	Assert(t, ok && len(multi.Errors()) == 2, "FirstSuccess() did not reject with every failure: %v", err)
+
+	calls = 0
+	_, err = Timeout(FirstSuccess(failing, func() Promise {
+		panic("backend exploded")
+	}, failing), time.Second).Wait()
+	var panicked *PanicError
+	Assert(t, errors.As(err, &panicked) && calls == 1, "FirstSuccess() did not reject on a panic: %v", err)
+}
+
+func TestPromise_AllKeyedLimit(t *testing.T) {
+	var mutex sync.Mutex
+	running, peak := 0, 0
+	factory := func(val Unknown) func() Promise {
+		return func() Promise {
+			mutex.Lock()
+			running += 1
+			if running > peak {
+				peak = running
+			}
+			mutex.Unlock()
+
+			return ResolveAfter(10*time.Millisecond, val).OnResolved(func(u Unknown) Unknown {
+				mutex.Lock()
+				running -= 1
+				mutex.Unlock()
+				return u
+			})
+		}
+	}
+
+	m := map[string]func() Promise{}
+	for i := 0; i < 6; i++ {
+		m[fmt.Sprintf("subsystem-%d", i)] = factory(i)
+	}
+
+	res, err := AllKeyedLimit(m, 2).Wait()
+	results, _ := (res).(map[string]Unknown)
+	Assert(t, err == nil && len(results) == 6, "AllKeyedLimit() did not resolve with every key: %v, %v", res, err)
+	Assert(t, results["subsystem-3"] == 3, "AllKeyedLimit() has an unexpected result: %v", results)
+	Assert(t, peak <= 2, "AllKeyedLimit() ran %d factories at once, limit 2", peak)
+
+	fail := errors.New("FAIL")
+	m["subsystem-2"] = func() Promise { return Reject(fail) }
+	_, err = AllKeyedLimit(m, 2).Wait()
+	keyed, ok := (err).(*KeyedError)
+	Assert(t, ok && keyed.Key == "subsystem-2" && keyed.Err == fail, "AllKeyedLimit() did not name the failing key: %v", err)
+
+	// the factory for the third key starts from a callback, once a slot frees up
+	panicking := map[string]func() Promise{
+		"a": func() Promise { return Resolve(1) },
+		"b": func() Promise { return Resolve(2) },
+		"c": func() Promise { panic("factory exploded") },
+	}
+	_, err = Timeout(AllKeyedLimit(panicking, 2), time.Second).Wait()
+	var panicked *PanicError
+	Assert(t, errors.As(err, &panicked), "AllKeyedLimit() did not reject on a panicking factory: %v", err)
+}
+
+func TestPromise_MapThen(t *testing.T) {
+	double := func(u Unknown) Unknown {
+		return (u).(int) * 2
+	}
+	res, err := All(MapThen(double, nil, Resolve(1), Resolve(2), Resolve(3))...).Wait()
+	values, _ := (res).([]Unknown)
+	Assert(t, err == nil && len(values) == 3 && values[0] == 2 && values[2] == 6, "MapThen() with All produced %v, %v", res, err)
+
+	fallback := func(error) Unknown {
+		return -1
+	}
+	res, err = All(MapThen(double, fallback, Resolve(1), Reject(errors.New("FAIL")))...).Wait()
+	values, _ = (res).([]Unknown)
+	Assert(t, err == nil && len(values) == 2 && values[1] == -1, "MapThen() did not apply onfail: %v, %v", res, err)
+}
+
+func TestPromise_Pipe(t *testing.T) {
+	var mutex sync.Mutex
+	next, running, peak := 0, 0, 0
+	received := map[int]bool{}
+
+	source := func() Promise {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if next == 20 {
+			return Resolve(Done)
+		}
+		next += 1
+		return Resolve(next)
+	}
+	sink := func(item Unknown) Promise {
+		mutex.Lock()
+		running += 1
+		if running > peak {
+			peak = running
+		}
+		mutex.Unlock()
+
+		return ResolveAfter(time.Millisecond, item).OnResolved(func(u Unknown) Unknown {
+			mutex.Lock()
+			defer mutex.Unlock()
+			running -= 1
+			received[(u).(int)] = true
+			return u
+		})
+	}
+
+	res, err := Pipe(source, sink, 3).Wait()
+	Assert(t, err == nil && res == 20, "Pipe() did not move every item: %v, %v", res, err)
+	Assert(t, len(received) == 20, "Sink received %d distinct items, expected 20", len(received))
+	Assert(t, peak <= 3, "Pipe() ran %d sinks at once, concurrency 3", peak)
+
+	fail := errors.New("FAIL")
+	next = 0
+	_, err = Pipe(source, func(Unknown) Promise { return Reject(fail) }, 3).Wait()
+	Assert(t, err == fail, "Pipe() did not reject with the sink failure: %v", err)
+
+	pulls := 0
+	_, err = Timeout(Pipe(func() Promise {
+		pulls += 1
+		if pulls == 2 {
+			panic("source exploded")
+		}
+		return Resolve(pulls)
+	}, func(u Unknown) Promise { return Resolve(u) }, 1), time.Second).Wait()
+	var panicked *PanicError
+	Assert(t, errors.As(err, &panicked), "Pipe() did not reject on a panicking source: %v", err)
+
+	next, sinks := 0, 0
+	_, err = Timeout(Pipe(source, func(u Unknown) Promise {
+		sinks += 1
+		if sinks == 2 {
+			panic("sink exploded")
+		}
+		return Resolve(u)
+	}, 1), time.Second).Wait()
+	Assert(t, errors.As(err, &panicked), "Pipe() did not reject on a panicking sink: %v", err)
+}
@@ -0,0 +1,94 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the rejection from a circuit breaker that is short-circuiting calls.
+var ErrCircuitOpen = NewPromiseError("circuit open")
+
+// CircuitOptions configures CircuitBreaker.
+type CircuitOptions struct {
+	Threshold int           // consecutive failures that open the circuit; at least 1
+	Cooldown  time.Duration // how long the circuit stays open before a trial call
+}
+
+type circuit struct {
+	mutex    sync.Mutex
+	options  CircuitOptions
+	factory  func() Promise
+	failures int
+	opened   time.Time
+	open     bool
+	trial    bool // a half-open trial call is in flight
+}
+
+// CircuitBreaker wraps factory in a breaker that counts consecutive rejections. Once Threshold of them occur,
+// the circuit opens, and calls reject immediately with ErrCircuitOpen, without calling factory, until Cooldown
+// has passed. The circuit is then half-open: the next call goes through as a trial, while concurrent calls
+// keep being rejected, and the trial's outcome closes the circuit again or reopens it. The returned function
+// is safe for concurrent use.
+func CircuitBreaker(options CircuitOptions, factory func() Promise) func() Promise {
+	if options.Threshold < 1 {
+		options.Threshold = 1
+	}
+	c := &circuit{options: options, factory: factory}
+	return c.call
+}
+
+func (c *circuit) call() Promise {
+	c.mutex.Lock()
+	trial := false
+	if c.open {
+		if c.trial || now().Before(c.opened.Add(c.options.Cooldown)) {
+			c.mutex.Unlock()
+			return Reject(ErrCircuitOpen)
+		}
+		c.trial = true
+		trial = true
+	}
+	c.mutex.Unlock()
+
+	prom := c.run(trial)
+	prom.Then(func(Unknown) Unknown {
+		c.record(true, trial)
+		return nil
+	}, func(error) Unknown {
+		c.record(false, trial)
+		return nil
+	})
+	return prom
+}
+
+// run calls the factory, turning a panic into a rejection so that a trial call still ends.
+func (c *circuit) run(trial bool) (prom Promise) {
+	defer func() {
+		if r := recover(); r != nil {
+			prom = Reject(recoveredError(r))
+		}
+	}()
+	return c.factory()
+}
+
+// record counts the outcome of a call. While the circuit is open, only the trial call decides whether it
+// closes; late outcomes of calls started before it opened are ignored.
+func (c *circuit) record(success, trial bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if trial {
+		c.trial = false
+	} else if c.open {
+		return
+	}
+	if success {
+		c.failures = 0
+		c.open = false
+		return
+	}
+	c.failures += 1
+	if c.open || c.failures >= c.options.Threshold {
+		c.open = true
+		c.opened = now()
+	}
+}
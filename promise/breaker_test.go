@@ -0,0 +1,101 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	fake := useFakeClock(t)
+	fail := errors.New("FAIL")
+
+	calls := 0
+	healthy := false
+	call := CircuitBreaker(CircuitOptions{Threshold: 2, Cooldown: time.Second}, func() Promise {
+		calls += 1
+		if healthy {
+			return Resolve("ok")
+		}
+		return Reject(fail)
+	})
+
+	// closed: failures pass through until the threshold opens the circuit
+	_, err := call().Wait()
+	Assert(t, err == fail, "Closed circuit did not pass the failure through: %v", err)
+	_, err = call().Wait()
+	Assert(t, err == fail, "Closed circuit did not pass the failure through: %v", err)
+
+	// open: calls are rejected without reaching the factory
+	_, err = call().Wait()
+	Assert(t, err == ErrCircuitOpen && calls == 2, "Open circuit did not short-circuit: %v after %d calls", err, calls)
+
+	// half-open: a failing trial reopens the circuit for another cooldown
+	fake.Advance(time.Second)
+	_, err = call().Wait()
+	Assert(t, err == fail && calls == 3, "Half-open circuit did not make a trial call: %v after %d calls", err, calls)
+	_, err = call().Wait()
+	Assert(t, err == ErrCircuitOpen, "Failed trial did not reopen the circuit: %v", err)
+
+	// half-open: a succeeding trial closes the circuit
+	fake.Advance(time.Second)
+	healthy = true
+	res, err := call().Wait()
+	Assert(t, err == nil && res == "ok", "Half-open trial did not succeed: %v, %v", res, err)
+
+	healthy = false
+	_, err = call().Wait()
+	Assert(t, err == fail, "Successful trial did not close the circuit: %v", err)
+	Assert(t, calls == 5, "Closed circuit made %d calls, expected 5", calls)
+}
+
+func TestCircuitBreaker_lateAndPanickingCalls(t *testing.T) {
+	fake := useFakeClock(t)
+	fail := errors.New("FAIL")
+
+	slow := []Resolver{}
+	mode := "slow"
+	call := CircuitBreaker(CircuitOptions{Threshold: 1, Cooldown: time.Second}, func() Promise {
+		switch mode {
+		case "slow", "trial":
+			return NewPromise(func(resolve Resolver, _ Rejector) error {
+				slow = append(slow, resolve)
+				return nil
+			})
+		case "panic":
+			panic("backend exploded")
+		}
+		return Reject(fail)
+	})
+
+	// two calls started while closed settle late, after the circuit opened
+	call()
+	call()
+	mode = "fail"
+	call().Wait()
+	slow[0]("late")
+	_, err := call().Wait()
+	Assert(t, err == ErrCircuitOpen, "A late result closed the circuit: %v", err)
+
+	// nor does a late result end the trial in flight
+	fake.Advance(time.Second)
+	mode = "trial"
+	call()
+	slow[1]("late")
+	_, err = call().Wait()
+	Assert(t, err == ErrCircuitOpen, "A second trial ran alongside the first: %v", err)
+
+	// a panicking trial reopens the circuit rather than leaving it half-open
+	slow[2]("recovered")
+	mode = "fail"
+	call().Wait()
+	fake.Advance(time.Second)
+	mode = "panic"
+	_, err = call().Wait()
+	var panicked *PanicError
+	Assert(t, errors.As(err, &panicked), "A panicking call did not reject: %v", err)
+	fake.Advance(time.Second)
+	mode = "fail"
+	_, err = call().Wait()
+	Assert(t, err == fail, "The circuit was stuck after a panicking trial: %v", err)
+}
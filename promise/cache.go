@@ -0,0 +1,69 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache memoizes the results of factories by key for a time to live, sharing the promise of a call in flight
+// like SingleFlight. Only resolutions are kept; a rejection is shared with the callers waiting on it, and the
+// next call after it runs the factory again.
+type Cache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	prom    Promise
+	ready   bool      // the promise resolved, and expires is set
+	expires time.Time // by the clock installed with SetClock
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// Get returns the promise cached for key while it is in flight or until ttl after it resolves, and otherwise
+// calls factory and caches its promise. factory runs without the lock held, so it may itself call Get.
+func (c *Cache) Get(key string, factory func() Promise) Promise {
+	c.mutex.Lock()
+	if entry, ok := c.entries[key]; ok && entry.live() {
+		c.mutex.Unlock()
+		return entry.prom
+	}
+	prom, settle := placeholder()
+	entry := &cacheEntry{prom: prom}
+	c.entries[key] = entry
+	c.mutex.Unlock()
+
+	prom.Then(func(Unknown) Unknown {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		entry.ready = true
+		entry.expires = now().Add(c.ttl)
+		return nil
+	}, func(error) Unknown {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		return nil
+	})
+
+	settle(factory)
+	return prom
+}
+
+// live reports whether the entry can be shared: it is in flight, or resolved and not yet expired. A rejected
+// entry is dead even before the callback that evicts it runs.
+func (e *cacheEntry) live() bool {
+	if e.prom.GetStatus() == PromiseStatusName[PromiseRejected] {
+		return false
+	}
+	return !e.ready || now().Before(e.expires)
+}
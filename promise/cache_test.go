@@ -0,0 +1,106 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	fake := useFakeClock(t)
+	cache := NewCache(time.Minute)
+	calls := 0
+	factory := func() Promise {
+		calls += 1
+		return ResolveAfter(10*time.Millisecond, calls)
+	}
+
+	first := cache.Get("key", factory)
+	shared := cache.Get("key", factory)
+	Assert(t, first == shared && calls == 1, "Cache did not share the promise in flight, made %d calls", calls)
+
+	fake.Advance(10 * time.Millisecond)
+	res, _ := cache.Get("key", factory).Wait()
+	Assert(t, res == 1 && calls == 1, "Cache missed a cached value: %v after %d calls", res, calls)
+
+	cache.Get("other", factory)
+	Assert(t, calls == 2, "Cache shared a value across keys")
+
+	fake.Advance(time.Minute)
+	prom := cache.Get("key", factory)
+	Assert(t, calls == 3, "Cache did not run the factory after the value expired")
+	fake.Advance(10 * time.Millisecond)
+	res, _ = prom.Wait()
+	Assert(t, res == 3, "Cache produced an unexpected value after expiry: %v", res)
+}
+
+func TestCache_rejection(t *testing.T) {
+	fake := useFakeClock(t)
+	cache := NewCache(time.Minute)
+	calls := 0
+	fail := errors.New("FAIL")
+	factory := func() Promise {
+		calls += 1
+		if calls == 1 {
+			return RejectAfter(10*time.Millisecond, fail)
+		}
+		return Resolve("recovered")
+	}
+
+	prom := cache.Get("key", factory)
+	Assert(t, cache.Get("key", factory) == prom, "Cache did not share a pending promise that later rejects")
+	fake.Advance(10 * time.Millisecond)
+	_, err := prom.Wait()
+	Assert(t, err == fail, "Cache did not pass through the rejection: %v", err)
+
+	res, err := cache.Get("key", factory).Wait()
+	Assert(t, err == nil && res == "recovered" && calls == 2, "Cache kept a rejection: %v, %v after %d calls", res, err, calls)
+}
+
+func TestCache_nested(t *testing.T) {
+	cache := NewCache(time.Minute)
+	outer := cache.Get("outer", func() Promise {
+		return cache.Get("inner", func() Promise {
+			return Resolve("inner")
+		})
+	})
+
+	result, _ := outer.Channel()
+	select {
+	case res := <-result:
+		Assert(t, res == "inner", "A nested Get() produced an unexpected result: %v", res)
+	case <-time.After(time.Second):
+		t.Fatalf("A factory calling Get() for another key deadlocked")
+	}
+}
+
+func TestCache_evictRejected(t *testing.T) {
+	cache := NewCache(time.Minute)
+	release := make(chan struct{})
+	SetExecutor(func(fn func()) {
+		go func() {
+			<-release // hold back callbacks, including the eviction
+			fn()
+		}()
+	})
+	defer SetExecutor(nil)
+
+	var reject Rejector
+	cache.Get("key", func() Promise {
+		// settles the cached promise inline, while its callbacks are held back
+		return NewPromiseOn(InlineExecutor, func(_ Resolver, r Rejector) error {
+			reject = r
+			return nil
+		})
+	})
+	reject(errors.New("FAIL"))
+	Assert(t, cache.entries["key"].prom.GetStatus() == "Rejected", "The cached promise did not reject")
+
+	calls := 0
+	cache.Get("key", func() Promise {
+		calls += 1
+		return Resolve("fresh")
+	})
+	close(release)
+	Assert(t, calls == 1, "Cache shared a rejected promise before evicting it")
+}
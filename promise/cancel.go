@@ -0,0 +1,82 @@
+package promise
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// ErrCanceled is the rejection of a promise that was canceled before it settled.
+var ErrCanceled = NewPromiseError("canceled")
+
+// CancelablePromise is a Promise whose pending work can be abandoned.
+type CancelablePromise interface {
+	Promise
+	Cancel()
+}
+
+type cancelablePromise struct {
+	*aPromise
+	once         sync.Once
+	cancelReject Rejector
+	onCancel     func()
+}
+
+// NewCancelablePromise creates a promise like NewPromise. Canceling it while it is still pending
+// runs onCancel, to stop the underlying work, and rejects it with ErrCanceled.
+func NewCancelablePromise(handler PromiseHandler, onCancel func()) CancelablePromise {
+	prom := &cancelablePromise{onCancel: onCancel}
+	prom.aPromise = NewPromise(func(resolve Resolver, reject Rejector) error {
+		prom.cancelReject = reject
+		return handler(resolve, reject)
+	}).(*aPromise)
+	return prom
+}
+
+// Cancel rejects the promise with ErrCanceled and runs its cancel hook, unless it has already settled.
+func (p *cancelablePromise) Cancel() {
+	p.once.Do(func() {
+		if p.GetStatus() != PromiseStatusName[PromisePending] {
+			return
+		}
+		logEvent(slog.LevelInfo, "canceled", p.aPromise, ErrCanceled)
+		if p.onCancel != nil {
+			p.onCancel()
+		}
+		p.cancelReject(ErrCanceled)
+	})
+}
+
+// WaitContext is Wait that gives up once ctx is done, and then also cancels the promise, so that abandoning
+// the wait stops the work. Promises derived from a cancelable promise are not cancelable themselves.
+func (p *cancelablePromise) WaitContext(ctx context.Context) (Unknown, error) {
+	res, err := p.aPromise.WaitContext(ctx)
+	if ctx.Err() != nil && err == ctx.Err() {
+		p.Cancel()
+	}
+	return res, err
+}
+
+// FuncContext is Func, with each call giving up once ctx is done like WaitContext, which also cancels p.
+func (p *cancelablePromise) FuncContext(ctx context.Context) func() (Unknown, error) {
+	return func() (Unknown, error) {
+		return p.WaitContext(ctx)
+	}
+}
+
+// AnyCancel is like Any, but cancels the remaining promises once one of them fulfills.
+func AnyCancel(proms ...CancelablePromise) Promise {
+	inputs := make([]Promise, len(proms))
+	for i, p := range proms {
+		inputs[i] = p
+	}
+
+	winner := Any(inputs...)
+	winner.Then(func(Unknown) Unknown {
+		for _, p := range proms {
+			p.Cancel()
+		}
+		return nil
+	}, nil)
+	return winner
+}
@@ -0,0 +1,100 @@
+package promise
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCancelablePromise(t *testing.T) {
+	canceled := make(chan bool, 1)
+	prom := NewCancelablePromise(func(resolve Resolver, reject Rejector) error {
+		return nil
+	}, func() {
+		canceled <- true
+	})
+
+	prom.Cancel()
+	prom.Cancel()
+
+	_, err := prom.Wait()
+	Assert(t, err == ErrCanceled, "Canceled promise has an unexpected rejection: %v", err)
+	Assert(t, len(canceled) == 1, "Cancel hook ran %d times, expected once", len(canceled))
+
+	settled := NewCancelablePromise(func(resolve Resolver, reject Rejector) error {
+		resolve("done")
+		return nil
+	}, func() {
+		t.Errorf("Cancel hook ran for a settled promise")
+	})
+	settled.Cancel()
+
+	res, err := settled.Wait()
+	Assert(t, err == nil && res == "done", "Cancel changed a settled promise: %v, %v", res, err)
+}
+
+func TestPromise_AnyCancel(t *testing.T) {
+	canceled := make(chan int, 3)
+
+	hedge := func(index int, d time.Duration) CancelablePromise {
+		stop := make(chan bool)
+		return NewCancelablePromise(func(resolve Resolver, reject Rejector) error {
+			go func() {
+				select {
+				case <-time.After(d):
+					resolve(index)
+				case <-stop:
+				}
+			}()
+			return nil
+		}, func() {
+			close(stop)
+			canceled <- index
+		})
+	}
+
+	res, err := AnyCancel(hedge(0, 500*time.Millisecond), hedge(1, 20*time.Millisecond), hedge(2, 500*time.Millisecond)).Wait()
+	Assert(t, err == nil && res == 1, "AnyCancel() produced an unexpected outcome: %v, %v", res, err)
+
+	seen := map[int]bool{}
+	for len(seen) < 2 {
+		select {
+		case index := <-canceled:
+			seen[index] = true
+		case <-time.After(200 * time.Millisecond):
+			t.Fatalf("AnyCancel() did not cancel the slower promises: %v", seen)
+		}
+	}
+	Assert(t, seen[0] && seen[2], "AnyCancel() canceled unexpected promises: %v", seen)
+}
+
+func TestCancelablePromise_WaitContext(t *testing.T) {
+	canceled := make(chan struct{})
+	prom := NewCancelablePromise(func(resolve Resolver, reject Rejector) error {
+		return nil
+	}, func() {
+		close(canceled)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := prom.WaitContext(ctx)
+	Assert(t, err == context.DeadlineExceeded, "WaitContext() did not give up with the context error: %v", err)
+
+	select {
+	case <-canceled:
+	default:
+		t.Fatalf("WaitContext() did not cancel the promise")
+	}
+	Assert(t, prom.GetStatus() == "Rejected", "Abandoned promise was not rejected, found %v", prom.GetStatus())
+
+	// a plain promise keeps running after the wait gives up
+	plain := NewPromise(func(resolve Resolver, reject Rejector) error {
+		return nil
+	})
+	_, err = plain.WaitContext(ctx)
+	Assert(t, err == context.DeadlineExceeded && plain.GetStatus() == "Pending", "WaitContext() settled a plain promise: %v", err)
+
+	res, err := Resolve("done").WaitContext(context.Background())
+	Assert(t, err == nil && res == "done", "WaitContext() did not return the result: %v, %v", res, err)
+}
@@ -0,0 +1,134 @@
+package promise
+
+import (
+	"context"
+	"sync"
+)
+
+// Merge fans the promises in to a single channel that delivers the outcome of each, in completion order, and
+// closes once all have settled. Receive from it in a select alongside other channels. The channel is buffered
+// for every input, so settling never blocks on a slow or absent reader, and abandoning it holds nothing up.
+func Merge(proms ...Promise) <-chan *PromiseOutcome {
+	out := make(chan *PromiseOutcome, len(proms))
+	if len(proms) == 0 {
+		close(out)
+		return out
+	}
+
+	var mutex sync.Mutex
+	remaining := len(proms)
+
+	for _, p := range proms {
+		func(prom Promise) {
+			deliver := func() {
+				mutex.Lock()
+				defer mutex.Unlock()
+				out <- prom.Outcome()
+				remaining -= 1
+				if remaining == 0 {
+					close(out)
+				}
+			}
+			prom.Then(func(Unknown) Unknown {
+				deliver()
+				return nil
+			}, func(error) Unknown {
+				deliver()
+				return nil
+			})
+		}(p)
+	}
+	return out
+}
+
+// AllSettledStream is the streaming counterpart of AllSettled, for batches too large to hold every outcome at
+// once: it delivers each outcome as its promise settles, in completion order rather than input order, and
+// closes the channel once all have settled. Outcomes that have been received and dropped can be collected.
+func AllSettledStream(proms ...Promise) <-chan *PromiseOutcome {
+	return Merge(proms...)
+}
+
+// MergeContext is Merge, except that the channel stops delivering and closes once ctx is done, even if some
+// promises are still pending. Outcomes are forwarded by a goroutine that exits when either happens; until the
+// channel is read, settles are absorbed by the buffer behind it, as with Merge.
+func MergeContext(ctx context.Context, proms ...Promise) <-chan *PromiseOutcome {
+	in := Merge(proms...)
+	out := make(chan *PromiseOutcome)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case outcome, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- outcome:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// FromOutcomeChannel produces a Promise that resolves with the result of the first fulfilled outcome received
+// from ch, such as one returned by Merge, and stops reading there. If ch closes with only rejected outcomes,
+// it rejects with a *MultiPromiseError of them, or with ErrChannelClosed if it delivered none. Pending
+// outcomes are skipped. A goroutine does the reading, so a channel abandoned while open keeps it blocked.
+func FromOutcomeChannel(ch <-chan *PromiseOutcome) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		go func() {
+			failed := []Promise{}
+			for outcome := range ch {
+				switch {
+				case outcome == nil:
+				case outcome.Status == PromiseStatusName[PromiseResolved]:
+					resolve(outcome.Result)
+					return
+				case outcome.Status == PromiseStatusName[PromiseRejected]:
+					failed = append(failed, FromOutcome(outcome))
+				}
+			}
+			if len(failed) == 0 {
+				reject(ErrChannelClosed)
+				return
+			}
+			reject(NewMultiPromiseError("no outcome fulfilled", failed))
+		}()
+		return nil
+	})
+}
+
+// WaitN blocks until n of the promises fulfill, and returns their values in completion order.
+// It returns as soon as the n-th value arrives, without waiting for the remaining promises,
+// and fails with a MultiPromiseError once too many promises have rejected for n to fulfill.
+func WaitN(n int, proms ...Promise) ([]Unknown, error) {
+	values := []Unknown{}
+	if n <= 0 {
+		return values, nil
+	}
+	if n > len(proms) {
+		return nil, NewPromiseError("WaitN requires at least as many promises as values")
+	}
+
+	failures := 0
+	for outcome := range Merge(proms...) {
+		if outcome.Status == PromiseStatusName[PromiseResolved] {
+			values = append(values, outcome.Result)
+			if len(values) == n {
+				return values, nil
+			}
+			continue
+		}
+
+		failures += 1
+		if len(proms)-failures < n {
+			return nil, NewMultiPromiseError("too many promises failed", proms)
+		}
+	}
+	return values, nil
+}
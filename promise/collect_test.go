@@ -0,0 +1,94 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPromise_WaitN(t *testing.T) {
+	never := NewPromise(func(resolve Resolver, reject Rejector) error {
+		return nil
+	})
+
+	values, err := WaitN(2, never, ResolveAfter(100*time.Millisecond, "slow"), ResolveAfter(10*time.Millisecond, "fast"))
+	Assert(t, err == nil, "WaitN() failed with an unexpected error: %s", err)
+	Assert(t, len(values) == 2, "WaitN() returned %d values, expected 2", len(values))
+	Assert(t, values[0] == "fast" && values[1] == "slow", "WaitN() did not return values in completion order: %v", values)
+
+	fail := errors.New("FAIL")
+	_, err = WaitN(2, never, Reject(fail), Reject(fail))
+	_, ok := (err).(*MultiPromiseError)
+	Assert(t, ok, "WaitN() did not fail once the count became unreachable: %v", err)
+
+	values, err = WaitN(0, never)
+	Assert(t, err == nil && len(values) == 0, "WaitN(0) did not return immediately: %v, %v", values, err)
+
+	_, err = WaitN(3, never)
+	Assert(t, err != nil, "WaitN() accepted more values than promises")
+}
+
+func TestPromise_Merge(t *testing.T) {
+	fail := errors.New("FAIL")
+	slow := ResolveAfter(20*time.Millisecond, "slow")
+
+	outcomes := []*PromiseOutcome{}
+	for outcome := range Merge(slow, Resolve("fast"), Reject(fail)) {
+		outcomes = append(outcomes, outcome)
+	}
+	Assert(t, len(outcomes) == 3, "Merge() delivered %d outcomes, expected 3", len(outcomes))
+	Assert(t, outcomes[2].Result == "slow", "Merge() did not deliver in completion order: %v", outcomes)
+
+	_, ok := <-Merge()
+	Assert(t, !ok, "Merge() of no promises did not close")
+}
+
+func TestPromise_MergeContext(t *testing.T) {
+	never := NewPromise(func(resolve Resolver, reject Rejector) error {
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	merged := MergeContext(ctx, Resolve("fast"), never)
+
+	outcome := <-merged
+	Assert(t, outcome.Result == "fast", "MergeContext() did not deliver the settled outcome: %v", outcome)
+
+	cancel()
+	_, ok := <-merged
+	Assert(t, !ok, "MergeContext() did not close once its context was done")
+}
+
+func TestPromise_AllSettledStream(t *testing.T) {
+	proms := make([]Promise, 1000)
+	for i := range proms {
+		if i%10 == 0 {
+			proms[i] = Reject(errors.New("FAIL"))
+		} else {
+			proms[i] = Resolve(i)
+		}
+	}
+
+	fulfilled, rejected := 0, 0
+	for outcome := range AllSettledStream(proms...) {
+		if outcome.Reason != nil {
+			rejected += 1
+		} else {
+			fulfilled += 1
+		}
+	}
+	Assert(t, fulfilled == 900 && rejected == 100, "AllSettledStream() delivered %d fulfilled and %d rejected", fulfilled, rejected)
+}
+
+func TestPromise_FromOutcomeChannel(t *testing.T) {
+	fail := errors.New("FAIL")
+	res, err := FromOutcomeChannel(Merge(Reject(fail), ResolveAfter(10*time.Millisecond, "winner"))).Wait()
+	Assert(t, err == nil && res == "winner", "FromOutcomeChannel() did not resolve with the fulfilled outcome: %v, %v", res, err)
+
+	_, err = FromOutcomeChannel(Merge(Reject(fail), RejectAfter(10*time.Millisecond, fail))).Wait()
+	multi, ok := (err).(*MultiPromiseError)
+	Assert(t, ok && len(multi.Errors()) == 2, "FromOutcomeChannel() did not aggregate the rejections: %v", err)
+
+	_, err = FromOutcomeChannel(Merge()).Wait()
+	Assert(t, err == ErrChannelClosed, "FromOutcomeChannel() of an empty channel did not reject: %v", err)
+}
@@ -0,0 +1,108 @@
+package promise
+
+import "sync"
+
+// Combiner collects the outcomes of a fixed number of inputs, by index, for combinators such as All and Any.
+// Resolve and Reject are safe to call concurrently; each index is recorded once, the first time either is called
+// for it, and the Combiner is done once every index has been recorded. A combinator records only the outcomes
+// that count towards completion, e.g. All records resolutions and rejects directly on the first failure.
+type Combiner struct {
+	mutex    sync.Mutex
+	results  []Unknown
+	reasons  []error
+	recorded []bool
+	resolved int
+	rejected int
+	done     chan struct{}
+	handlers []func()
+}
+
+func NewCombiner(total int) *Combiner {
+	c := &Combiner{
+		results:  make([]Unknown, total),
+		reasons:  make([]error, total),
+		recorded: make([]bool, total),
+		done:     make(chan struct{}),
+	}
+	if total == 0 {
+		close(c.done)
+	}
+	return c
+}
+
+// Resolve records val as the result of input i.
+func (c *Combiner) Resolve(i int, val Unknown) {
+	c.record(i, func() {
+		c.results[i] = val
+		c.resolved += 1
+	})
+}
+
+// Reject records err as the reason input i failed.
+func (c *Combiner) Reject(i int, err error) {
+	c.record(i, func() {
+		c.reasons[i] = err
+		c.rejected += 1
+	})
+}
+
+func (c *Combiner) record(i int, update func()) {
+	c.mutex.Lock()
+	if c.recorded[i] {
+		c.mutex.Unlock()
+		return
+	}
+	c.recorded[i] = true
+	update()
+	var handlers []func()
+	if c.resolved+c.rejected == len(c.recorded) {
+		handlers, c.handlers = c.handlers, nil
+		close(c.done)
+	}
+	c.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler()
+	}
+}
+
+// Done returns a channel that is closed once every input has been recorded.
+func (c *Combiner) Done() <-chan struct{} {
+	return c.done
+}
+
+// OnDone registers handler to run once every input has been recorded, on the goroutine that records the last
+// of them. If that has already happened, handler runs immediately.
+func (c *Combiner) OnDone(handler func()) {
+	c.mutex.Lock()
+	select {
+	case <-c.done:
+		c.mutex.Unlock()
+		handler()
+	default:
+		c.handlers = append(c.handlers, handler)
+		c.mutex.Unlock()
+	}
+}
+
+// Counts reports how many inputs have been recorded as resolved and as rejected so far.
+func (c *Combiner) Counts() (resolved, rejected int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.resolved, c.rejected
+}
+
+// Results returns the recorded results by index, with nil for inputs not recorded as resolved. Once the
+// Combiner is done, the slice is no longer written and may be handed on, as All does.
+func (c *Combiner) Results() []Unknown {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.results
+}
+
+// Errors returns the recorded reasons by index, with nil for inputs not recorded as rejected.
+func (c *Combiner) Errors() []error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.reasons
+}
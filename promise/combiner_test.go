@@ -0,0 +1,49 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestCombiner(t *testing.T) {
+	combiner := NewCombiner(100)
+	calls := 0
+	combiner.OnDone(func() { calls += 1 })
+
+	fail := errors.New("FAIL")
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				combiner.Resolve(i, i)
+			} else {
+				combiner.Reject(i, fail)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			combiner.Resolve(i, "duplicate") // at most one record per index counts
+		}(i)
+	}
+	wg.Wait()
+
+	<-combiner.Done()
+	resolved, rejected := combiner.Counts()
+	Assert(t, resolved+rejected == 100 && calls == 1, "Combiner recorded %d and %d, and ran OnDone %d times", resolved, rejected, calls)
+	results, reasons := combiner.Results(), combiner.Errors()
+	for i := 0; i < 100; i++ {
+		Assert(t, (results[i] == nil) != (reasons[i] == nil), "Combiner recorded index %d twice or not at all", i)
+	}
+
+	combiner.OnDone(func() { calls += 1 })
+	Assert(t, calls == 2, "OnDone() did not run a late handler immediately")
+
+	select {
+	case <-NewCombiner(0).Done():
+	default:
+		t.Errorf("A Combiner of no inputs is not done")
+	}
+}
@@ -0,0 +1,102 @@
+package promise
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// StrictMode enables extra checks that catch misbehaving handlers during development.
+var StrictMode bool
+
+// OnDoubleSettle receives the error reported by StrictMode when a handler settles its
+// promise and then also returns an error. When nil, StrictMode panics instead.
+var OnDoubleSettle func(*DoubleSettleError)
+
+// DoubleSettleError describes a handler that settled its promise and also returned an error.
+type DoubleSettleError struct {
+	Status string  // status of the promise when the handler returned
+	Result Unknown // result the promise had already resolved with, if any
+	Reason error   // error returned by the handler
+}
+
+func (e *DoubleSettleError) Error() string {
+	return fmt.Sprintf("Promise error: handler returned error (%s) after the promise was %s",
+		e.Reason, strings.ToLower(e.Status))
+}
+
+func (e *DoubleSettleError) Unwrap() error {
+	return e.Reason
+}
+
+func reportDoubleSettle(p *aPromise, reason error) {
+	outcome := p.Outcome()
+	err := &DoubleSettleError{
+		Status: outcome.Status,
+		Result: outcome.Result,
+		Reason: reason,
+	}
+	if OnDoubleSettle != nil {
+		OnDoubleSettle(err)
+		return
+	}
+	panic(err)
+}
+
+// TrackLineage makes each derived promise record the promise it was derived from, for Parent. It keeps every
+// ancestor of a live promise reachable, so whole chains stay in memory for as long as their last promise.
+var TrackLineage bool
+
+// CaptureUnhandled enables reporting of rejected promises that are garbage collected
+// before any Then, Catch, Wait or Channel observed their rejection.
+var CaptureUnhandled bool
+
+// OnUnhandledRejection receives the error of an unobserved rejected promise when
+// CaptureUnhandled is set. When nil, the rejection is logged.
+var OnUnhandledRejection func(error)
+
+func trackUnhandled(p *aPromise) {
+	p.unhandled = true
+	runtime.SetFinalizer(p, func(p *aPromise) {
+		if OnUnhandledRejection != nil {
+			OnUnhandledRejection(p.reject)
+			return
+		}
+		log.Printf("Promise error: unhandled rejection: %s", p.reject)
+	})
+}
+
+// OnSlowPromise receives a promise created by NewPromiseWithWarn that is still pending once its
+// warning delay elapsed. When nil, a warning is logged with the stack that created the promise.
+var OnSlowPromise func(p Promise, elapsed time.Duration)
+
+// NewPromiseWithWarn creates a Promise like NewPromise, and reports it to OnSlowPromise if it has not
+// settled within d, to surface promises that stall during development. The promise is not rejected,
+// and the watchdog timer stops as soon as it settles.
+func NewPromiseWithWarn(d time.Duration, handler PromiseHandler) Promise {
+	stack := debug.Stack()
+	prom := newPromise(traits{}, handler)
+
+	timer := afterFunc(d, func() {
+		if prom.GetStatus() != PromiseStatusName[PromisePending] {
+			return
+		}
+		if OnSlowPromise != nil {
+			OnSlowPromise(prom, d)
+			return
+		}
+		log.Printf("Promise warning: still pending after %s, created at:\n%s", d, stack)
+	})
+
+	prom.mutex.Lock()
+	defer prom.mutex.Unlock()
+	if prom.status != PromisePending {
+		timer.Stop()
+	} else {
+		prom.hooks = append(prom.hooks, func() { timer.Stop() })
+	}
+	return prom
+}
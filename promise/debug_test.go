@@ -0,0 +1,169 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestStrictMode_doubleSettle(t *testing.T) {
+	StrictMode = true
+	defer func() {
+		StrictMode = false
+		OnDoubleSettle = nil
+	}()
+
+	var reported *DoubleSettleError
+	OnDoubleSettle = func(e *DoubleSettleError) {
+		reported = e
+	}
+
+	fail := errors.New("late failure")
+	prom := NewPromise(func(resolve Resolver, reject Rejector) error {
+		resolve(1)
+		return fail
+	})
+
+	Assert(t, reported != nil, "StrictMode did not report a double settle")
+	Assert(t, errors.Is(reported, fail), "Double settle error does not wrap the handler error: %v", reported)
+	Assert(t, reported.Result == 1, "Double settle error has unexpected result: %v", reported.Result)
+	Assert(t, prom.GetStatus() == "Resolved", "First settle did not win, found %v", prom.GetStatus())
+
+	reported = nil
+	NewPromise(func(resolve Resolver, reject Rejector) error {
+		return fail
+	})
+	Assert(t, reported == nil, "StrictMode reported a handler that only returned an error")
+}
+
+func TestStrictMode_panicsWithoutHook(t *testing.T) {
+	StrictMode = true
+	defer func() {
+		StrictMode = false
+	}()
+
+	defer func() {
+		r := recover()
+		_, ok := r.(*DoubleSettleError)
+		Assert(t, ok, "StrictMode did not panic with a DoubleSettleError: %v", r)
+	}()
+
+	NewPromise(func(resolve Resolver, reject Rejector) error {
+		reject(errors.New("first"))
+		return errors.New("second")
+	})
+}
+
+func TestCaptureUnhandled(t *testing.T) {
+	CaptureUnhandled = true
+	defer func() {
+		CaptureUnhandled = false
+		OnUnhandledRejection = nil
+	}()
+
+	reported := make(chan error, 4)
+	OnUnhandledRejection = func(e error) {
+		reported <- e
+	}
+
+	fail := errors.New("nobody listened")
+	handled := errors.New("somebody listened")
+
+	func() {
+		Reject(fail)
+		Reject(handled).Catch(func(e error) Unknown {
+			return true
+		})
+	}()
+
+	for i := 0; i < 50; i++ {
+		runtime.GC()
+		select {
+		case e := <-reported:
+			Assert(t, e == fail, "Unexpected unhandled rejection reported: %v", e)
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Errorf("Unhandled rejection was never reported")
+}
+
+func TestPromise_concurrentInspection(t *testing.T) {
+	var resolve Resolver
+	prom := NewPromise(func(r Resolver, _ Rejector) error {
+		resolve = r
+		return nil
+	})
+
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			_ = fmt.Sprint(prom)
+			_ = prom.Outcome()
+			_ = prom.GetStatus()
+		}
+	}()
+
+	resolve("settled")
+	<-done
+
+	res, err := prom.Wait()
+	Assert(t, err == nil && res == "settled", "Promise did not settle as expected: %v, %v", res, err)
+}
+
+func TestNewPromiseWithWarn(t *testing.T) {
+	fake := useFakeClock(t)
+	var slow []Promise
+	OnSlowPromise = func(p Promise, elapsed time.Duration) {
+		slow = append(slow, p)
+	}
+	defer func() {
+		OnSlowPromise = nil
+	}()
+
+	var resolve Resolver
+	stalled := NewPromiseWithWarn(time.Second, func(Resolver, Rejector) error {
+		return nil
+	})
+	settled := NewPromiseWithWarn(time.Second, func(r Resolver, _ Rejector) error {
+		resolve = r
+		return nil
+	})
+	immediate := NewPromiseWithWarn(time.Second, func(r Resolver, _ Rejector) error {
+		r("now")
+		return nil
+	})
+
+	resolve("done")
+	Assert(t, fake.Pending() == 1, "Settled promises left %d watchdog timers, expected 1", fake.Pending())
+
+	fake.Advance(time.Second)
+	Assert(t, len(slow) == 1 && slow[0] == stalled, "OnSlowPromise() reported unexpected promises: %v", slow)
+	Assert(t, stalled.GetStatus() == "Pending", "The watchdog settled the slow promise, found %v", stalled.GetStatus())
+	Assert(t, settled.GetStatus() == "Resolved" && immediate.GetStatus() == "Resolved", "Watched promises did not settle")
+}
+
+func TestTrackLineage(t *testing.T) {
+	TrackLineage = true
+	defer func() {
+		TrackLineage = false
+	}()
+
+	root := Resolve(1)
+	first := root.OnResolved(func(u Unknown) Unknown { return u })
+	second := first.Catch(func(error) Unknown { return nil })
+	third := second.OrValue(0)
+
+	lineage := []Promise{}
+	for p := third; p != nil; p = p.Parent() {
+		lineage = append(lineage, p)
+	}
+	Assert(t, len(lineage) == 4, "Walked %d promises up the chain, expected 4", len(lineage))
+	Assert(t, lineage[1] == second && lineage[2] == first && lineage[3] == root, "Lineage does not match the chain: %v", lineage)
+
+	TrackLineage = false
+	Assert(t, root.OnResolved(func(u Unknown) Unknown { return u }).Parent() == nil, "Parent was recorded without TrackLineage")
+}
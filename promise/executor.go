@@ -0,0 +1,180 @@
+package promise
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Executor runs the callbacks a promise dispatches to its consumers once it settles.
+type Executor func(func())
+
+// GoExecutor runs each callback on its own goroutine. It is the default executor.
+func GoExecutor(fn func()) {
+	go fn()
+}
+
+// InlineExecutor runs each callback synchronously on the dispatching goroutine. With it installed,
+// chaining off an already settled promise settles the derived promise before Then returns,
+// and settling a promise runs its consumers before resolve or reject return.
+// Callbacks must not block waiting on other promises, since nothing else runs them.
+func InlineExecutor(fn func()) {
+	fn()
+}
+
+var executor = func() *atomic.Value {
+	value := &atomic.Value{}
+	value.Store(Executor(GoExecutor))
+	return value
+}()
+
+// SetExecutor installs the executor used to dispatch promise callbacks; nil restores GoExecutor.
+func SetExecutor(e Executor) {
+	if e == nil {
+		e = GoExecutor
+	}
+	executor.Store(e)
+}
+
+// dispatch runs fn through the executor the promise was created on, or else the installed executor.
+func (t traits) dispatch(fn func()) {
+	if t.executor != nil {
+		t.executor(fn)
+		return
+	}
+	executor.Load().(Executor)(fn)
+}
+
+// NewPromiseOn creates a Promise whose callbacks, and those of every promise derived from it, run through
+// dispatcher rather than the installed executor, such as one that hands them to a UI or game thread.
+// A dispatcher that runs the functions it receives one at a time, in order, runs the callbacks of a single
+// promise in the order they were registered, and a derived promise's callbacks after its parent's.
+func NewPromiseOn(dispatcher Executor, handler PromiseHandler) Promise {
+	return newPromise(traits{executor: dispatcher}, handler)
+}
+
+// NewTrampolineExecutor creates an executor that runs callbacks on the dispatching goroutine without spawning
+// goroutines, like InlineExecutor, but without nesting: a callback dispatched while another is running is
+// queued and run once the running one returns, so settling a deep chain takes constant stack. Install it with
+// SetExecutor, or for a single chain with NewPromiseOn.
+//
+// Whichever goroutine finds the trampoline idle runs the queue, including callbacks queued from other
+// goroutines meanwhile. As with InlineExecutor, callbacks must not block waiting on other promises.
+func NewTrampolineExecutor() Executor {
+	var mutex sync.Mutex
+	var queue []func()
+	running := false
+
+	return func(fn func()) {
+		mutex.Lock()
+		queue = append(queue, fn)
+		if running {
+			mutex.Unlock()
+			return
+		}
+		running = true
+		for len(queue) > 0 {
+			next := queue[0]
+			queue = queue[1:]
+			mutex.Unlock()
+			next()
+			mutex.Lock()
+		}
+		running = false
+		mutex.Unlock()
+	}
+}
+
+// RunSync runs fn with a trampoline executor installed, so that promise callbacks dispatched meanwhile run on
+// the dispatching goroutine, in dispatch order, rather than on goroutines of their own. The previous executor
+// is restored when fn returns. Promises that wait on timers or other goroutines still settle on those.
+//
+// The executor is global, so RunSync must not overlap with other code using promises, such as parallel
+// tests. Inside fn, Wait on a promise that is still pending only returns if another goroutine settles it;
+// while a callback is running, waiting on a promise that one of the queued callbacks would settle deadlocks.
+func RunSync(fn func()) {
+	previous := executor.Load().(Executor)
+	SetExecutor(NewTrampolineExecutor())
+	defer SetExecutor(previous)
+	fn()
+}
+
+// Scheduler is an executor that can be paused: callbacks dispatched while it is paused are buffered,
+// and run in dispatch order, through the next executor, once it resumes. Install it with
+// SetExecutor(scheduler.Execute).
+//
+// Buffered callbacks, and everything they reference, stay in memory until Resume. A scheduler with a
+// limit bounds that: once the buffer holds limit callbacks, dispatchers block until it resumes, so a
+// limited scheduler must not be paused by, or resumed from, a goroutine that dispatches callbacks.
+type Scheduler struct {
+	mutex    sync.Mutex
+	drained  *sync.Cond
+	next     Executor
+	limit    int
+	paused   bool
+	draining bool
+	queue    []func()
+}
+
+// NewScheduler creates a running Scheduler that dispatches through next (nil for GoExecutor),
+// buffering at most limit callbacks while paused (0 for no limit).
+func NewScheduler(next Executor, limit int) *Scheduler {
+	if next == nil {
+		next = GoExecutor
+	}
+	s := &Scheduler{next: next, limit: limit}
+	s.drained = sync.NewCond(&s.mutex)
+	return s
+}
+
+// Execute dispatches fn, or buffers it while the scheduler is paused.
+func (s *Scheduler) Execute(fn func()) {
+	s.mutex.Lock()
+	for s.paused && s.limit > 0 && len(s.queue) >= s.limit {
+		s.drained.Wait()
+	}
+	if s.paused || s.draining {
+		// keep dispatch order behind the buffered callbacks
+		s.queue = append(s.queue, fn)
+		s.mutex.Unlock()
+		return
+	}
+	s.mutex.Unlock()
+	s.next(fn)
+}
+
+// Pause buffers subsequently dispatched callbacks until Resume.
+func (s *Scheduler) Pause() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.paused = true
+}
+
+// Resume dispatches the buffered callbacks in order, and stops buffering.
+func (s *Scheduler) Resume() {
+	s.mutex.Lock()
+	if s.draining {
+		s.paused = false
+		s.mutex.Unlock()
+		return
+	}
+	s.paused = false
+	s.draining = true
+	for len(s.queue) > 0 && !s.paused {
+		fn := s.queue[0]
+		s.queue = s.queue[1:]
+		s.drained.Broadcast()
+		s.mutex.Unlock()
+		s.next(fn)
+		s.mutex.Lock()
+	}
+	s.draining = false
+	s.drained.Broadcast()
+	s.mutex.Unlock()
+}
+
+// Buffered reports the number of callbacks waiting for Resume.
+func (s *Scheduler) Buffered() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.queue)
+}
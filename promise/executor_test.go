@@ -0,0 +1,236 @@
+package promise
+
+import (
+	"errors"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+	"time"
+)
+
+func TestInlineExecutor(t *testing.T) {
+	SetExecutor(InlineExecutor)
+	defer SetExecutor(nil)
+
+	fail := errors.New("FAIL")
+	derived := Reject(fail).Then(nil, nil)
+	outcome := derived.Outcome()
+	Assert(t, outcome.Status == "Rejected", "Derived promise did not settle synchronously, found %v", outcome.Status)
+	Assert(t, outcome.Reason == fail, "Derived promise has an unexpected rejection: %v", outcome.Reason)
+
+	derived = Resolve(1).OnResolved(func(u Unknown) Unknown {
+		return (u).(int) + 1
+	})
+	outcome = derived.Outcome()
+	Assert(t, outcome.Status == "Resolved" && outcome.Result == 2, "Derived promise did not resolve synchronously: %v", outcome)
+
+	var resolve Resolver
+	pending := NewPromise(func(r Resolver, _ Rejector) error {
+		resolve = r
+		return nil
+	})
+	derived = pending.OnResolved(func(u Unknown) Unknown {
+		return (u).(int) * 10
+	})
+	resolve(4)
+	outcome = derived.Outcome()
+	Assert(t, outcome.Status == "Resolved" && outcome.Result == 40, "Consumers did not run before resolve returned: %v", outcome)
+}
+
+func TestScheduler(t *testing.T) {
+	scheduler := NewScheduler(InlineExecutor, 0)
+	SetExecutor(scheduler.Execute)
+	defer SetExecutor(nil)
+
+	order := []int{}
+	scheduler.Pause()
+
+	root := Resolve(1)
+	for i := 1; i <= 3; i++ {
+		step := i
+		root.OnResolved(func(u Unknown) Unknown {
+			order = append(order, step)
+			return nil
+		})
+	}
+
+	Assert(t, len(order) == 0, "Paused scheduler ran callbacks: %v", order)
+	Assert(t, scheduler.Buffered() == 3, "Paused scheduler buffered %d callbacks, expected 3", scheduler.Buffered())
+
+	scheduler.Resume()
+	Assert(t, len(order) == 3 && order[0] == 1 && order[1] == 2 && order[2] == 3,
+		"Resumed scheduler ran callbacks out of order: %v", order)
+	Assert(t, scheduler.Buffered() == 0, "Resumed scheduler still buffers %d callbacks", scheduler.Buffered())
+
+	root.OnResolved(func(u Unknown) Unknown {
+		order = append(order, 4)
+		return nil
+	})
+	Assert(t, len(order) == 4, "Running scheduler did not dispatch immediately: %v", order)
+}
+
+func TestScheduler_limit(t *testing.T) {
+	scheduler := NewScheduler(InlineExecutor, 1)
+	scheduler.Pause()
+
+	ran := make(chan int, 2)
+	scheduler.Execute(func() { ran <- 1 })
+
+	blocked := make(chan bool)
+	go func() {
+		scheduler.Execute(func() { ran <- 2 })
+		close(blocked)
+	}()
+
+	select {
+	case <-blocked:
+		t.Fatalf("Dispatch did not block on a full scheduler buffer")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	scheduler.Resume()
+	<-blocked
+
+	Assert(t, <-ran == 1, "Buffered callback did not run first")
+	Assert(t, <-ran == 2, "Blocked callback did not run after resuming")
+}
+
+func TestNewPromiseOn(t *testing.T) {
+	queue := make(chan func(), 16)
+	go func() {
+		for fn := range queue {
+			fn()
+		}
+	}()
+	defer close(queue)
+
+	looped := 0
+	loop := func(fn func()) {
+		queue <- func() {
+			looped += 1
+			fn()
+		}
+	}
+
+	var order []int
+	prom := NewPromiseOn(loop, func(resolve Resolver, reject Rejector) error {
+		resolve(1)
+		return nil
+	})
+
+	res, err := prom.Then(func(u Unknown) Unknown {
+		order = append(order, u.(int))
+		return u.(int) + 1
+	}, nil).Then(func(u Unknown) Unknown {
+		order = append(order, u.(int))
+		return u.(int) + 1
+	}, nil).Wait()
+
+	Assert(t, err == nil && res == 3, "NewPromiseOn() chain produced an unexpected outcome: %v, %v", res, err)
+	Assert(t, len(order) == 2 && order[0] == 1 && order[1] == 2, "Callbacks ran out of order: %v", order)
+	Assert(t, looped >= 3, "Derived callbacks did not run through the dispatcher, %d dispatched", looped)
+}
+
+func TestTrampolineExecutor(t *testing.T) {
+	SetExecutor(NewTrampolineExecutor())
+	defer SetExecutor(nil)
+
+	var resolve Resolver
+	root := NewPromise(func(r Resolver, _ Rejector) error {
+		resolve = r
+		return nil
+	})
+
+	depth := func() int {
+		return runtime.Callers(0, make([]uintptr, 4096))
+	}
+	var first, last int
+	chain := root.OnResolved(func(u Unknown) Unknown {
+		first = depth()
+		return u
+	})
+	for i := 0; i < 1000; i++ {
+		chain = chain.OnResolved(func(u Unknown) Unknown {
+			return (u).(int) + 1
+		})
+	}
+	chain = chain.OnResolved(func(u Unknown) Unknown {
+		last = depth()
+		return u
+	})
+
+	resolve(0)
+	outcome := chain.Outcome()
+	Assert(t, outcome.Status == "Resolved" && outcome.Result == 1000, "Chain did not settle before resolve returned: %v", outcome)
+	Assert(t, last == first, "Callbacks nested on the stack: depth %d at the end of the chain, %d at its start", last, first)
+}
+
+// BenchmarkChain_1000 reports the goroutines started per 1000-deep chain, where the runtime tracks them.
+func BenchmarkChain_1000(b *testing.B) {
+	created := []metrics.Sample{{Name: "/sched/goroutines-created:goroutines"}}
+	goroutines := func() uint64 {
+		metrics.Read(created)
+		if created[0].Value.Kind() != metrics.KindUint64 {
+			return 0
+		}
+		return created[0].Value.Uint64()
+	}
+
+	for _, bench := range []struct {
+		name     string
+		executor Executor
+	}{
+		{"go", GoExecutor},
+		{"trampoline", NewTrampolineExecutor()},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			start := goroutines()
+			for i := 0; i < b.N; i++ {
+				var resolve Resolver
+				chain := NewPromiseOn(bench.executor, func(r Resolver, _ Rejector) error {
+					resolve = r
+					return nil
+				})
+				for j := 0; j < 1000; j++ {
+					chain = chain.OnResolved(func(u Unknown) Unknown {
+						return u
+					})
+				}
+				resolve(nil)
+				chain.Wait()
+			}
+			b.ReportMetric(float64(goroutines()-start)/float64(b.N), "goroutines/op")
+		})
+	}
+}
+
+func TestRunSync(t *testing.T) {
+	SetExecutor(InlineExecutor)
+	defer SetExecutor(nil)
+
+	RunSync(func() {
+		order := []int{}
+		chain := Resolve(1).OnResolved(func(u Unknown) Unknown {
+			order = append(order, (u).(int))
+			return (u).(int) + 1
+		})
+		Resolve(10).OnResolved(func(u Unknown) Unknown {
+			order = append(order, (u).(int))
+			return nil
+		})
+		chain.OnResolved(func(u Unknown) Unknown {
+			order = append(order, (u).(int))
+			return nil
+		})
+
+		Assert(t, len(order) == 3 && order[0] == 1 && order[1] == 10 && order[2] == 2,
+			"RunSync() did not run callbacks synchronously in dispatch order: %v", order)
+	})
+
+	ran := false
+	Resolve(nil).OnResolved(func(Unknown) Unknown {
+		ran = true
+		return nil
+	})
+	Assert(t, ran, "RunSync() did not restore the previous executor")
+}
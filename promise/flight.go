@@ -0,0 +1,74 @@
+package promise
+
+import (
+	"sync"
+)
+
+// SingleFlight coalesces concurrent calls for the same key into a single call of the factory.
+type SingleFlight struct {
+	mutex    sync.Mutex
+	inflight map[string]Promise
+}
+
+func NewSingleFlight() *SingleFlight {
+	return &SingleFlight{
+		inflight: make(map[string]Promise),
+	}
+}
+
+// Do returns the pending promise for key if there is one, and otherwise calls factory and shares its promise
+// with every caller for key until it settles. Calls after that run factory again; nothing is cached. factory
+// runs without the lock held, so it may itself call Do, and a slow factory holds up only the callers for its key.
+func (s *SingleFlight) Do(key string, factory func() Promise) Promise {
+	s.mutex.Lock()
+	if prom, ok := s.inflight[key]; ok && prom.GetStatus() == PromiseStatusName[PromisePending] {
+		s.mutex.Unlock()
+		return prom
+	}
+	prom, settle := placeholder()
+	s.inflight[key] = prom
+	s.mutex.Unlock()
+
+	release := func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if s.inflight[key] == prom {
+			delete(s.inflight, key)
+		}
+	}
+	prom.Then(func(Unknown) Unknown {
+		release()
+		return nil
+	}, func(error) Unknown {
+		release()
+		return nil
+	})
+
+	settle(factory)
+	return prom
+}
+
+// placeholder returns a pending promise to publish before its factory runs, and the function that runs the
+// factory and settles the placeholder like the promise it produces. A panic in the factory, or a nil promise,
+// rejects the placeholder.
+func placeholder() (Promise, func(factory func() Promise)) {
+	var resolve Resolver
+	var reject Rejector
+	prom := NewPromise(func(r1 Resolver, r2 Rejector) error {
+		resolve, reject = r1, r2
+		return nil
+	})
+
+	return prom, func(factory func() Promise) {
+		defer func() {
+			if r := recover(); r != nil {
+				reject(recoveredError(r))
+			}
+		}()
+		if next := factory(); isNilPromise(next) {
+			reject(ErrNilPromise)
+		} else {
+			resolve(next)
+		}
+	}
+}
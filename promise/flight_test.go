@@ -0,0 +1,61 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleFlight(t *testing.T) {
+	flight := NewSingleFlight()
+	var calls int32
+	factory := func() Promise {
+		atomic.AddInt32(&calls, 1)
+		return ResolveAfter(20*time.Millisecond, "value")
+	}
+
+	var wg sync.WaitGroup
+	results := make([]Unknown, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = flight.Do("key", factory).Wait()
+		}(i)
+	}
+	wg.Wait()
+
+	Assert(t, atomic.LoadInt32(&calls) == 1, "SingleFlight ran the factory %d times for concurrent callers", calls)
+	for i, res := range results {
+		Assert(t, res == "value", "Caller %d received an unexpected result: %v", i, res)
+	}
+
+	flight.Do("key", factory).Wait()
+	Assert(t, atomic.LoadInt32(&calls) == 2, "SingleFlight did not release the key once settled")
+
+	flight.Do("other", factory).Wait()
+	Assert(t, atomic.LoadInt32(&calls) == 3, "SingleFlight shared a promise across keys")
+}
+
+func TestSingleFlight_nested(t *testing.T) {
+	flight := NewSingleFlight()
+	outer := flight.Do("outer", func() Promise {
+		return flight.Do("inner", func() Promise {
+			return Resolve("inner")
+		})
+	})
+
+	result, _ := outer.Channel()
+	select {
+	case res := <-result:
+		Assert(t, res == "inner", "A nested Do() produced an unexpected result: %v", res)
+	case <-time.After(time.Second):
+		t.Fatalf("A factory calling Do() for another key deadlocked")
+	}
+
+	_, err := flight.Do("panic", func() Promise { panic("exploded") }).Wait()
+	var panicked *PanicError
+	Assert(t, errors.As(err, &panicked), "A panicking factory did not reject: %v", err)
+}
@@ -0,0 +1,149 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Copier produces an independent copy of a result, or fails if it cannot.
+type Copier func(Unknown) (Unknown, error)
+
+// Freeze produces a Promise that settles like p, except that every consumer, whether through Then, Wait,
+// WaitOutcome, Await or Channel, receives its own copy of the result, made by copier, so that mutating it cannot
+// affect the others. Outcome still reports the original. A copy that fails rejects that consumer's view with the copier's error.
+//
+// A nil copier uses DeepCopy. Copying costs time and memory on every consumption, proportional to the size
+// of the result, so reserve Freeze for mutable results that really are shared.
+func Freeze(p Promise, copier Copier) Promise {
+	if copier == nil {
+		copier = DeepCopy
+	}
+	prom := newPromise(traits{}, func(resolve Resolver, reject Rejector) error {
+		p.subscribe(func(status uint, val Unknown, err error) {
+			if status == PromiseResolved {
+				resolve(val)
+			} else {
+				reject(err)
+			}
+		})
+		return nil
+	})
+	prom.copier = copier
+	return prom
+}
+
+// copying wraps handle so that it receives a copy of a resolved value.
+func (p *aPromise) copying(handle func(uint, Unknown, error)) func(uint, Unknown, error) {
+	return func(status uint, val Unknown, err error) {
+		if status == PromiseResolved {
+			if val, err = p.copier(val); err != nil {
+				status = PromiseRejected
+			}
+		}
+		handle(status, val, err)
+	}
+}
+
+// consumerOutcome is Outcome as a consumer receives it, with the result copied for a frozen promise.
+func (p *aPromise) consumerOutcome() *PromiseOutcome {
+	outcome := p.Outcome()
+	if p.copier != nil && outcome.Status == PromiseStatusName[PromiseResolved] {
+		if val, err := p.copier(outcome.Result); err != nil {
+			outcome.Status, outcome.Result, outcome.Reason = PromiseStatusName[PromiseRejected], nil, err
+		} else {
+			outcome.Result = val
+		}
+	}
+	return outcome
+}
+
+// DeepCopy copies slices, maps, arrays, pointers, interfaces and the exported fields of structs recursively.
+// Unexported struct fields are copied shallowly. It fails on channels and functions, and does not handle cyclic
+// values; supply a Copier of your own for those.
+func DeepCopy(val Unknown) (Unknown, error) {
+	if val == nil {
+		return nil, nil
+	}
+	copied, err := deepCopy(reflect.ValueOf(val))
+	if err != nil {
+		return nil, err
+	}
+	return copied.Interface(), nil
+}
+
+func deepCopy(v reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		return out, copyElements(out, v)
+
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		return out, copyElements(out, v)
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := deepCopy(iter.Value())
+			if err != nil {
+				return v, err
+			}
+			out.SetMapIndex(iter.Key(), elem)
+		}
+		return out, nil
+
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := deepCopy(v.Elem())
+		if err != nil {
+			return v, err
+		}
+		if v.Kind() == reflect.Interface {
+			out := reflect.New(v.Type()).Elem()
+			out.Set(elem)
+			return out, nil
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(elem)
+		return out, nil
+
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if !out.Field(i).CanSet() {
+				continue
+			}
+			field, err := deepCopy(v.Field(i))
+			if err != nil {
+				return v, err
+			}
+			out.Field(i).Set(field)
+		}
+		return out, nil
+
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return v, NewPromiseError(fmt.Sprintf("DeepCopy cannot copy a %s", v.Type()))
+	}
+	return v, nil
+}
+
+func copyElements(out, v reflect.Value) error {
+	for i := 0; i < v.Len(); i++ {
+		elem, err := deepCopy(v.Index(i))
+		if err != nil {
+			return err
+		}
+		out.Index(i).Set(elem)
+	}
+	return nil
+}
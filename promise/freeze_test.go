@@ -0,0 +1,68 @@
+package promise
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreeze(t *testing.T) {
+	type config struct {
+		Hosts  []string
+		Limits map[string]int
+	}
+	original := &config{Hosts: []string{"a", "b"}, Limits: map[string]int{"rps": 10}}
+	frozen := Freeze(Resolve(original), nil)
+
+	mutated := frozen.OnResolved(func(u Unknown) Unknown {
+		c := (u).(*config)
+		c.Hosts[0] = "mutated"
+		c.Limits["rps"] = 0
+		return c
+	})
+	mutated.Wait()
+
+	res, err := frozen.Wait()
+	sibling, _ := (res).(*config)
+	Assert(t, err == nil && sibling != nil, "Frozen promise did not resolve with a copy: %v, %v", res, err)
+	Assert(t, sibling != original, "Frozen promise shared the original value")
+	Assert(t, sibling.Hosts[0] == "a" && sibling.Limits["rps"] == 10, "A sibling's mutation was visible: %v", sibling)
+	Assert(t, original.Hosts[0] == "a" && original.Limits["rps"] == 10, "A consumer mutated the original: %v", original)
+
+	_, err = Freeze(Resolve(make(chan int)), nil).Wait()
+	Assert(t, err != nil, "DeepCopy accepted a channel")
+
+	copies := 0
+	counted := Freeze(Resolve([]int{1}), func(u Unknown) (Unknown, error) {
+		copies += 1
+		return append([]int{}, (u).([]int)...), nil
+	})
+	counted.Wait()
+	counted.Wait()
+	Assert(t, copies == 2, "Freeze() made %d copies for 2 consumers", copies)
+}
+
+func TestFreeze_settledConsumers(t *testing.T) {
+	original := []int{1, 2}
+	frozen := Freeze(Resolve(original), nil)
+	frozen.Wait()
+
+	res, err := Await(frozen)
+	Assert(t, err == nil, "Await() of a frozen promise failed: %v", err)
+	(res).([]int)[0] = 100
+
+	outcome := frozen.WaitOutcome()
+	Assert(t, outcome.Status == "Resolved", "WaitOutcome() of a frozen promise has status %v", outcome.Status)
+	(outcome.Result).([]int)[1] = 200
+
+	res, _ = frozen.Wait()
+	Assert(t, (res).([]int)[0] == 1 && (res).([]int)[1] == 2, "A consumer's mutation was visible: %v", res)
+	Assert(t, original[0] == 1 && original[1] == 2, "Await() or WaitOutcome() handed out the original: %v", original)
+
+	res, _ = Materialize(frozen).Wait()
+	((res).(*PromiseOutcome).Result).([]int)[0] = 300
+	Assert(t, original[0] == 1, "Materialize() handed out the original: %v", original)
+
+	_, salvage := TimeoutWithSalvage(frozen, time.Second)
+	((<-salvage).Result).([]int)[0] = 400
+	Assert(t, original[0] == 1, "TimeoutWithSalvage() handed out the original: %v", original)
+}
@@ -0,0 +1,107 @@
+package promise
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrCycle is wrapped by the rejection of DependencyGraph.Resolve when providers depend on each other in a cycle.
+var ErrCycle = NewPromiseError("dependency cycle")
+
+// DependencyGraph runs named providers in dependency order, each at most once, e.g. to bootstrap services.
+type DependencyGraph struct {
+	mutex     sync.Mutex
+	providers map[string]provider
+	promises  map[string]Promise
+}
+
+type provider struct {
+	deps    []string
+	factory func(map[string]Unknown) Promise
+}
+
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{
+		providers: make(map[string]provider),
+		promises:  make(map[string]Promise),
+	}
+}
+
+// Provide registers the factory for name, which receives the results of deps keyed by name. Register every
+// provider before resolving any name that depends on it; a provider that already ran is not replaced.
+func (g *DependencyGraph) Provide(name string, deps []string, factory func(resolved map[string]Unknown) Promise) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.providers[name] = provider{deps: deps, factory: factory}
+}
+
+// Resolve produces a Promise of the result of the provider for name, running its dependencies first, with
+// independent ones concurrently. Each provider runs once, however many names depend on it. It rejects with
+// an error wrapping ErrCycle if the dependencies of name contain a cycle, and with the first error of a
+// provider it depends on.
+func (g *DependencyGraph) Resolve(name string) Promise {
+	g.mutex.Lock()
+	err := g.check(name, []string{}, map[string]bool{})
+	g.mutex.Unlock()
+	if err != nil {
+		return Reject(err)
+	}
+	return g.node(name)
+}
+
+// check walks the dependencies of name depth first, reporting a cycle back to path, or a missing provider.
+func (g *DependencyGraph) check(name string, path []string, checked map[string]bool) error {
+	for i, visiting := range path {
+		if visiting == name {
+			return fmt.Errorf("%w: %s", ErrCycle, strings.Join(append(path[i:], name), " -> "))
+		}
+	}
+	if checked[name] {
+		return nil
+	}
+	p, ok := g.providers[name]
+	if !ok {
+		return NewPromiseError(fmt.Sprintf("no provider for %q", name))
+	}
+	for _, dep := range p.deps {
+		if err := g.check(dep, append(path, name), checked); err != nil {
+			return err
+		}
+	}
+	checked[name] = true
+	return nil
+}
+
+// node returns the promise of name, creating it on first use. The graph must have been checked.
+func (g *DependencyGraph) node(name string) Promise {
+	g.mutex.Lock()
+	if prom, ok := g.promises[name]; ok {
+		g.mutex.Unlock()
+		return prom
+	}
+	var resolve Resolver
+	var reject Rejector
+	prom := NewPromise(func(r1 Resolver, r2 Rejector) error {
+		resolve, reject = r1, r2
+		return nil
+	})
+	g.promises[name] = prom
+	p := g.providers[name]
+	g.mutex.Unlock()
+
+	deps := make([]Promise, len(p.deps))
+	for i, dep := range p.deps {
+		deps[i] = g.node(dep)
+	}
+
+	All(deps...).Then(func(u Unknown) Unknown {
+		values := (u).([]Unknown)
+		resolved := make(map[string]Unknown, len(values))
+		for i, dep := range p.deps {
+			resolved[dep] = values[i]
+		}
+		return p.factory(resolved)
+	}, nil).Then(resolve, reject)
+	return prom
+}
@@ -0,0 +1,59 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	var mutex sync.Mutex
+	runs := map[string]int{}
+	provider := func(name string) func(map[string]Unknown) Promise {
+		return func(resolved map[string]Unknown) Promise {
+			mutex.Lock()
+			runs[name] += 1
+			mutex.Unlock()
+
+			total := 1
+			for _, v := range resolved {
+				total += (v).(int)
+			}
+			return Resolve(total)
+		}
+	}
+
+	// a diamond: app depends on cache and store, which both depend on config
+	graph := NewDependencyGraph()
+	graph.Provide("config", nil, provider("config"))
+	graph.Provide("cache", []string{"config"}, provider("cache"))
+	graph.Provide("store", []string{"config"}, provider("store"))
+	graph.Provide("app", []string{"cache", "store"}, provider("app"))
+
+	res, err := graph.Resolve("app").Wait()
+	Assert(t, err == nil && res == 5, "Resolve() produced an unexpected outcome: %v, %v", res, err)
+	Assert(t, runs["config"] == 1, "The shared dependency ran %d times", runs["config"])
+
+	res, _ = graph.Resolve("store").Wait()
+	Assert(t, res == 2 && runs["store"] == 1, "Resolve() ran a provider again: %v, %v", res, runs)
+
+	fail := errors.New("FAIL")
+	graph.Provide("broken", nil, func(map[string]Unknown) Promise { return Reject(fail) })
+	graph.Provide("dependent", []string{"broken"}, provider("dependent"))
+	_, err = graph.Resolve("dependent").Wait()
+	Assert(t, err == fail && runs["dependent"] == 0, "A failed dependency did not reject its dependents: %v", err)
+
+	_, err = graph.Resolve("missing").Wait()
+	Assert(t, err != nil, "Resolve() of an unknown name did not reject")
+}
+
+func TestDependencyGraph_cycle(t *testing.T) {
+	graph := NewDependencyGraph()
+	graph.Provide("a", []string{"b"}, func(map[string]Unknown) Promise { return Resolve(1) })
+	graph.Provide("b", []string{"c"}, func(map[string]Unknown) Promise { return Resolve(2) })
+	graph.Provide("c", []string{"a"}, func(map[string]Unknown) Promise { return Resolve(3) })
+
+	_, err := graph.Resolve("a").Wait()
+	Assert(t, errors.Is(err, ErrCycle), "Resolve() did not detect the cycle: %v", err)
+	Assert(t, err != nil && err.Error() == "Promise error: dependency cycle: a -> b -> c -> a", "Unexpected cycle report: %v", err)
+}
@@ -0,0 +1,185 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+)
+
+// Do runs fn immediately on the calling goroutine and produces a Promise already settled with its outcome.
+// A panic in fn becomes a rejection.
+func Do(fn func() (Unknown, error)) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoveredError(r)
+			}
+		}()
+
+		val, err := fn()
+		if err != nil {
+			return err
+		}
+		resolve(val)
+		return nil
+	})
+}
+
+// Spread adapts fn into a Resolver that receives the elements of a resolved slice, such as the result of All,
+// as positional arguments. Resolving with anything other than a slice rejects the derived promise.
+func Spread(fn func(...Unknown) Unknown) Resolver {
+	return func(u Unknown) Unknown {
+		if args, ok := (u).([]Unknown); ok {
+			return fn(args...)
+		}
+
+		value := reflect.ValueOf(u)
+		if value.Kind() != reflect.Slice {
+			panic(NewPromiseError(fmt.Sprintf("Spread requires a slice, found %T", u)))
+		}
+
+		args := make([]Unknown, value.Len())
+		for i := range args {
+			args[i] = value.Index(i).Interface()
+		}
+		return fn(args...)
+	}
+}
+
+// recoveredError converts a recovered panic value into an error suitable for rejection.
+func recoveredError(r interface{}) error {
+	return &PanicError{Value: r, Stack: debug.Stack()}
+}
+
+// ContextPromise produces a Promise that rejects with ctx.Err() once ctx is done, and otherwise never settles.
+// Race it against other work to bound that work by ctx. No goroutine watches ctx; the context holds the
+// promise until it is done or canceled, so a context that is never canceled keeps the promise alive.
+func ContextPromise(ctx context.Context) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		context.AfterFunc(ctx, func() {
+			reject(ctx.Err())
+		})
+		return nil
+	})
+}
+
+// FromWaitGroup produces a Promise that resolves with nil once wg reaches zero. A goroutine blocks in wg.Wait
+// until then. The promise never rejects, since a WaitGroup carries no error; for fan-in that must report
+// failures, have each task produce a Promise and combine them with All or AllSettled instead.
+func FromWaitGroup(wg *sync.WaitGroup) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		go func() {
+			wg.Wait()
+			resolve(nil)
+		}()
+		return nil
+	})
+}
+
+// ErrChannelClosed is the rejection from FromChannel when its values channel closes before delivering a value.
+var ErrChannelClosed = NewPromiseError("channel closed without a value")
+
+// ChannelPair is a values channel and an errors channel that a Then handler can return to be adopted as a
+// Promise, since a handler has a single result.
+type ChannelPair struct {
+	Values <-chan Unknown
+	Errors <-chan error
+}
+
+// FromChannel produces a Promise settled by the first of values or errs to deliver. It resolves with the first
+// value, rejects with the first error, or rejects with ErrChannelClosed if values closes first. A nil errs is
+// never selected. A goroutine receives until then; a channel that never delivers keeps it blocked.
+func FromChannel(values <-chan Unknown, errs <-chan error) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		go func() {
+			select {
+			case val, ok := <-values:
+				if !ok {
+					reject(ErrChannelClosed)
+					return
+				}
+				resolve(val)
+			case err := <-errs:
+				reject(err)
+			}
+		}()
+		return nil
+	})
+}
+
+// adopt converts the channel and slice shapes recognized by Then into a Promise, and returns anything else as is.
+func adopt(res Unknown) Unknown {
+	switch c := (res).(type) {
+	case <-chan Unknown:
+		return FromChannel(c, nil)
+	case ChannelPair:
+		return FromChannel(c.Values, c.Errors)
+	case []Promise:
+		return All(c...)
+	}
+	return res
+}
+
+// Rethrow builds a Rejector that rejects again with added context: the rejection reason is wrapped as
+// fmt.Errorf(format+": %w", args..., err), so errors.Is and errors.As still find the original through it.
+// Any error a Rejector returns becomes the derived rejection as is, so one built by hand with %w keeps the
+// chain as well.
+func Rethrow(format string, args ...interface{}) Rejector {
+	return func(err error) Unknown {
+		return fmt.Errorf(format+": %w", append(args, err)...)
+	}
+}
+
+// Await blocks until p settles, and returns its result or error, like Wait. It is meant for handlers that
+// need a sub-result, such as a NewPromise handler running its own goroutine. When p has already settled,
+// Await reads its outcome directly, without dispatching anything through the executor.
+//
+// Awaiting a pending promise needs the executor to run p's callbacks meanwhile. With a serial executor
+// installed, such as InlineExecutor, a trampoline, a Scheduler or a NewPromiseOn event loop, never await a
+// pending promise from a callback that executor is running: the callbacks that would settle p queue behind
+// the blocked one, and deadlock. Chain with Then or ThenPromise from callbacks instead.
+func Await(p Promise) (Unknown, error) {
+	if outcome := p.consumerOutcome(); outcome.Status != PromiseStatusName[PromisePending] {
+		p.subscribe(func(uint, Unknown, error) {}) // the rejection, if any, is handled here
+		return outcome.Result, outcome.Reason
+	}
+	return p.Wait()
+}
+
+// Tee produces two independent promises that each settle with the outcome of p, to hand to different
+// consumers. Unlike two Then calls on p, each branch is a first-class promise of its own.
+func Tee(p Promise) (a, b Promise) {
+	mirror := func() Promise {
+		return NewPromise(func(resolve Resolver, reject Rejector) error {
+			p.Then(resolve, reject)
+			return nil
+		})
+	}
+	return mirror(), mirror()
+}
+
+// FromSignal produces a Promise that resolves with nil on the first receive from ch, which includes ch
+// closing. A goroutine waits for that and then exits; if ch never fires, it stays blocked for good, along
+// with the promise.
+func FromSignal(ch <-chan struct{}) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		go func() {
+			<-ch
+			resolve(nil)
+		}()
+		return nil
+	})
+}
+
+// FromSignalValue is FromSignal, resolving with the first value received from ch, or the zero value of T if
+// ch closes first.
+func FromSignalValue[T any](ch <-chan T) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		go func() {
+			resolve(<-ch)
+		}()
+		return nil
+	})
+}
@@ -0,0 +1,234 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPromise_Do(t *testing.T) {
+	ran := false
+	prom := Do(func() (Unknown, error) {
+		ran = true
+		return 42, nil
+	})
+
+	Assert(t, ran, "Do() did not run the function inline")
+	Assert(t, prom.GetStatus() == "Resolved", "Do() promise was not settled, found %v", prom.GetStatus())
+
+	res, err := prom.Wait()
+	Assert(t, err == nil && res == 42, "Do() produced an unexpected outcome: %v, %v", res, err)
+
+	fail := errors.New("FOILED!")
+	_, err = Do(func() (Unknown, error) {
+		return nil, fail
+	}).Wait()
+	Assert(t, err == fail, "Do() did not reject with the returned error: %v", err)
+
+	prom = Do(func() (Unknown, error) {
+		panic("exploded")
+	})
+	Assert(t, prom.GetStatus() == "Rejected", "Do() did not reject on panic, found %v", prom.GetStatus())
+
+	_, err = prom.Wait()
+	Assert(t, err != nil && err.Error() == "Promise error: panic: exploded", "Do() panic produced an unexpected error: %v", err)
+}
+
+func TestPromise_Spread(t *testing.T) {
+	res, err := All(Resolve("a"), Resolve(2), Resolve(true)).OnResolved(Spread(func(args ...Unknown) Unknown {
+		return fmt.Sprintf("%v-%v-%v", args[0], args[1], args[2])
+	})).Wait()
+	Assert(t, err == nil, "Spread() failed with an unexpected error: %s", err)
+	Assert(t, res == "a-2-true", "Spread() passed unexpected arguments: %v", res)
+
+	res, err = Resolve([]int{1, 2, 3}).OnResolved(Spread(func(args ...Unknown) Unknown {
+		return len(args)
+	})).Wait()
+	Assert(t, err == nil && res == 3, "Spread() did not accept a typed slice: %v, %v", res, err)
+
+	_, err = Resolve(7).OnResolved(Spread(func(args ...Unknown) Unknown {
+		t.Errorf("Spread() invoked the function for a non-slice value")
+		return nil
+	})).Wait()
+	Assert(t, err != nil, "Spread() did not reject a non-slice value")
+}
+
+func TestPromise_ContextPromise(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	work := NewPromise(func(resolve Resolver, reject Rejector) error {
+		return nil // never settles
+	})
+
+	raced := Race(work, ContextPromise(ctx))
+	cancel()
+
+	_, err := raced.Wait()
+	Assert(t, err == context.Canceled, "ContextPromise() did not reject with the context error: %v", err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	res, err := Race(Resolve("done"), ContextPromise(ctx)).Wait()
+	Assert(t, err == nil && res == "done", "ContextPromise() settled before its context was done: %v, %v", res, err)
+}
+
+func TestPromise_FromWaitGroup(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	prom := FromWaitGroup(&wg)
+	Assert(t, prom.GetStatus() == "Pending", "FromWaitGroup() settled before the group finished")
+
+	wg.Done()
+	res, err := prom.Wait()
+	Assert(t, err == nil && res == nil, "FromWaitGroup() did not resolve with nil: %v, %v", res, err)
+}
+
+func TestPromise_FromChannel(t *testing.T) {
+	values := make(chan Unknown, 1)
+	values <- "first"
+	res, err := FromChannel(values, nil).Wait()
+	Assert(t, err == nil && res == "first", "FromChannel() did not resolve with the first value: %v, %v", res, err)
+
+	errs := make(chan error, 1)
+	errs <- errors.New("FAIL")
+	res, err = FromChannel(make(chan Unknown), errs).Wait()
+	Assert(t, err != nil && err.Error() == "FAIL", "FromChannel() did not reject with the first error: %v, %v", res, err)
+
+	closed := make(chan Unknown)
+	close(closed)
+	_, err = FromChannel(closed, nil).Wait()
+	Assert(t, err == ErrChannelClosed, "FromChannel() did not reject a closed channel: %v", err)
+}
+
+func TestPromise_Then_adoptsChannel(t *testing.T) {
+	res, err := Resolve(2).Then(func(u Unknown) Unknown {
+		values := make(chan Unknown, 1)
+		values <- u.(int) * 2
+		return (<-chan Unknown)(values)
+	}, nil).Wait()
+	Assert(t, err == nil && res == 4, "Then() did not adopt a returned channel: %v, %v", res, err)
+
+	res, err = Resolve(2).Then(func(u Unknown) Unknown {
+		errs := make(chan error, 1)
+		errs <- errors.New("FAIL")
+		return ChannelPair{Values: make(chan Unknown), Errors: errs}
+	}, nil).Wait()
+	Assert(t, err != nil && err.Error() == "FAIL", "Then() did not adopt a returned channel pair: %v, %v", res, err)
+
+	res, err = Reject(errors.New("FAIL")).Catch(func(error) Unknown {
+		values := make(chan Unknown, 1)
+		values <- "fallback"
+		return (<-chan Unknown)(values)
+	}).Wait()
+	Assert(t, err == nil && res == "fallback", "Catch() did not adopt a returned channel: %v, %v", res, err)
+
+	res, err = Reject(errors.New("FAIL")).Catch(func(error) Unknown {
+		return []Promise{Resolve(1), Resolve(2)}
+	}).Wait()
+	values, _ := (res).([]Unknown)
+	Assert(t, err == nil && len(values) == 2, "Catch() did not join a returned []Promise: %v, %v", res, err)
+}
+
+func TestPromise_Rethrow(t *testing.T) {
+	fail := errors.New("connection refused")
+
+	_, err := Reject(fail).Catch(Rethrow("loading %s", "config")).Wait()
+	Assert(t, err != nil && err.Error() == "loading config: connection refused", "Rethrow() produced an unexpected message: %v", err)
+	Assert(t, errors.Is(err, fail), "Rethrow() lost the original error: %v", err)
+
+	_, err = Reject(fail).Catch(Rethrow("retrying")).Catch(Rethrow("giving up")).Wait()
+	Assert(t, errors.Is(err, fail), "Chained Rethrow() lost the original error: %v", err)
+}
+
+func TestPromise_Await(t *testing.T) {
+	prom := NewPromise(func(resolve Resolver, reject Rejector) error {
+		go func() {
+			res, err := Await(Resolve(2).OnResolved(func(u Unknown) Unknown {
+				return (u).(int) * 21
+			}))
+			if err != nil {
+				reject(err)
+				return
+			}
+			resolve(res)
+		}()
+		return nil
+	})
+	res, err := prom.Wait()
+	Assert(t, err == nil && res == 42, "Await() inside a handler produced an unexpected outcome: %v, %v", res, err)
+
+	// a settled promise is read directly, so awaiting it from an inline callback cannot deadlock
+	SetExecutor(InlineExecutor)
+	defer SetExecutor(nil)
+
+	fail := errors.New("FAIL")
+	settled := Reject(fail)
+	res, err = Resolve(nil).OnResolved(func(Unknown) Unknown {
+		_, err := Await(settled)
+		return err
+	}).Wait()
+	Assert(t, err == fail, "Await() of a settled promise produced an unexpected outcome: %v, %v", res, err)
+}
+
+func TestPromise_Tee(t *testing.T) {
+	a, b := Tee(ResolveAfter(10*time.Millisecond, "shared"))
+	Assert(t, a != b, "Tee() returned the same promise twice")
+
+	first, err1 := a.Wait()
+	second, err2 := b.Wait()
+	Assert(t, err1 == nil && err2 == nil && first == "shared" && second == "shared",
+		"Tee() branches settled differently: %v, %v / %v, %v", first, err1, second, err2)
+
+	fail := errors.New("FAIL")
+	a, b = Tee(Reject(fail))
+	_, err1 = a.Wait()
+	_, err2 = b.Wait()
+	Assert(t, err1 == fail && err2 == fail, "Tee() of a settled rejection differs: %v / %v", err1, err2)
+}
+
+func TestPromise_FromSignal(t *testing.T) {
+	abort := make(chan struct{})
+	raced := Race(FromSignal(abort), ResolveAfter(time.Second, "finished"))
+	close(abort)
+	res, err := raced.Wait()
+	Assert(t, err == nil && res == nil, "FromSignal() did not win the race once signaled: %v, %v", res, err)
+
+	values := make(chan int, 1)
+	values <- 42
+	res, err = FromSignalValue(values).Wait()
+	Assert(t, err == nil && res == 42, "FromSignalValue() did not resolve with the first value: %v, %v", res, err)
+}
+
+func TestPromise_Then_joinsPromiseSlice(t *testing.T) {
+	// each node resolves with its children, which the chain joins with All, two levels deep
+	var expand func(depth int) Resolver
+	expand = func(depth int) Resolver {
+		return func(u Unknown) Unknown {
+			if depth == 0 {
+				return u
+			}
+			children := []Promise{}
+			for i := 0; i < 2; i++ {
+				children = append(children, Resolve(fmt.Sprintf("%v.%d", u, i)).Then(expand(depth-1), nil))
+			}
+			return children
+		}
+	}
+
+	res, err := Resolve("root").Then(expand(2), nil).Wait()
+	level1, _ := (res).([]Unknown)
+	Assert(t, err == nil && len(level1) == 2, "Then() did not join the returned promises: %v, %v", res, err)
+	level2, _ := (level1[1]).([]Unknown)
+	Assert(t, len(level2) == 2 && level2[0] == "root.1.0", "Then() did not join nested promises: %v", level1)
+
+	fail := errors.New("FAIL")
+	_, err = Resolve(nil).Then(func(Unknown) Unknown {
+		return []Promise{Resolve(1), Reject(fail)}
+	}, nil).Wait()
+	Assert(t, err == fail, "Then() did not reject with a failed child: %v", err)
+}
@@ -0,0 +1,84 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+var logger atomic.Pointer[slog.Logger]
+
+// SetLogger enables structured logging of promise lifecycle events to l; nil disables it (the default).
+// Events are written in the order they happen by a single goroutine, which buffers up to logBacklog of them,
+// so a slow handler only delays settling once that backlog fills.
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+const logBacklog = 256
+
+type logRecord struct {
+	logger *slog.Logger
+	level  slog.Level
+	msg    string
+	attrs  []slog.Attr
+}
+
+var (
+	logQueue  = make(chan logRecord, logBacklog)
+	logWriter sync.Once
+)
+
+type nameKey struct{}
+
+// WithName derives a Promise that settles like p and is logged under name. Like other values, the name
+// carries over to promises derived from the result.
+func (p *aPromise) WithName(name string) Promise {
+	return p.WithValue(nameKey{}, name)
+}
+
+func logSettle(p *aPromise, status uint, err error) {
+	switch {
+	case status != PromiseRejected:
+		logEvent(slog.LevelDebug, "resolved", p, nil)
+	case errors.Is(err, ErrDeadlineExceeded):
+		logEvent(slog.LevelInfo, "timed out", p, err)
+	default:
+		logEvent(slog.LevelInfo, "rejected", p, err)
+	}
+}
+
+// logEvent records a lifecycle event of p, with its name, current status and age.
+func logEvent(level slog.Level, event string, p *aPromise, err error) {
+	l := logger.Load()
+	if l == nil {
+		return
+	}
+
+	var attrs []slog.Attr
+	if name, ok := p.Value(nameKey{}).(string); ok {
+		attrs = append(attrs, slog.String("name", name))
+	}
+
+	p.mutex.Lock()
+	attrs = append(attrs,
+		slog.String("status", PromiseStatusName[p.status]),
+		slog.Duration("duration", now().Sub(p.created)),
+	)
+	p.mutex.Unlock()
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	logWriter.Do(func() {
+		go func() {
+			for r := range logQueue {
+				r.logger.LogAttrs(context.Background(), r.level, r.msg, r.attrs...)
+			}
+		}()
+	})
+	logQueue <- logRecord{logger: l, level: level, msg: "promise " + event, attrs: attrs}
+}
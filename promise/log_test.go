@@ -0,0 +1,85 @@
+package promise
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(data []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(data)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+func TestSetLogger(t *testing.T) {
+	output := &syncBuffer{}
+	SetLogger(slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+
+	Resolve(7).Wait()
+	Reject(errors.New("FOILED!")).Catch(func(e error) Unknown {
+		return true
+	}).Wait()
+
+	// log writes happen asynchronously to the settle path
+	for i := 0; i < 50 && !strings.Contains(output.String(), "error="); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	logged := output.String()
+	Assert(t, strings.Contains(logged, `msg="promise resolved" status=Resolved`), "Resolution was not logged: %s", logged)
+	Assert(t, strings.Contains(logged, `msg="promise rejected" status=Rejected`), "Rejection was not logged: %s", logged)
+	Assert(t, strings.Contains(logged, "error=FOILED!"), "Rejection error was not logged: %s", logged)
+}
+
+func TestSetLogger_order(t *testing.T) {
+	clock := useFakeClock(t)
+	output := &syncBuffer{}
+	SetLogger(slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+
+	fetch := NewCancelablePromise(func(Resolver, Rejector) error { return nil }, nil)
+	named := fetch.WithName("fetch")
+	fetch.Cancel()
+	named.Wait()
+
+	slow := NewPromise(func(Resolver, Rejector) error { return nil }).WithName("slow").Deadline(now().Add(time.Second))
+	clock.Advance(time.Second)
+	slow.Wait()
+
+	for i := 0; i < 50 && !strings.Contains(output.String(), "timed out"); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	var events []string
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		if !strings.Contains(line, "name=") && !strings.Contains(line, "error=\"Promise error: canceled\"") {
+			continue // settled by a promise left over from an earlier test
+		}
+		event := line[strings.Index(line, "msg="):]
+		events = append(events, event[:strings.Index(event, " status=")])
+	}
+	expected := []string{
+		`msg="promise canceled"`,
+		`msg="promise rejected"`,
+		`msg="promise rejected" name=fetch`,
+		`msg="promise timed out" name=slow`,
+	}
+	Assert(t, strings.Join(events, "\n") == strings.Join(expected, "\n"), "Events were logged out of order: %q", events)
+}
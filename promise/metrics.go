@@ -0,0 +1,49 @@
+package promise
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+// counters tally promise lifecycle transitions for EnableExpvar.
+var counters struct {
+	created  atomic.Int64
+	resolved atomic.Int64
+	rejected atomic.Int64
+	timedOut atomic.Int64
+}
+
+var publishExpvar sync.Once
+
+// EnableExpvar publishes promise counters as the expvar variable "promise", a map of the total number
+// of promises created, resolved, rejected and timed out, and of those currently pending. The counters
+// run regardless; this only makes them visible, e.g. under /debug/vars. Calling it again has no effect.
+func EnableExpvar() {
+	publishExpvar.Do(func() {
+		expvar.Publish("promise", expvar.Func(func() interface{} {
+			return promiseCounts()
+		}))
+	})
+}
+
+func promiseCounts() map[string]int64 {
+	created := counters.created.Load()
+	resolved := counters.resolved.Load()
+	rejected := counters.rejected.Load()
+	return map[string]int64{
+		"created":   created,
+		"resolved":  resolved,
+		"rejected":  rejected,
+		"timed_out": counters.timedOut.Load(),
+		"pending":   created - resolved - rejected,
+	}
+}
+
+func countSettle(status uint) {
+	if status == PromiseRejected {
+		counters.rejected.Add(1)
+	} else {
+		counters.resolved.Add(1)
+	}
+}
@@ -0,0 +1,43 @@
+package promise
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestEnableExpvar(t *testing.T) {
+	fake := useFakeClock(t)
+	EnableExpvar()
+	EnableExpvar()
+
+	read := func() map[string]int64 {
+		counts := map[string]int64{}
+		err := json.Unmarshal([]byte(expvar.Get("promise").String()), &counts)
+		Assert(t, err == nil, "Published counters are not a JSON map: %v", err)
+		return counts
+	}
+
+	before := read()
+	var resolve Resolver
+	NewPromise(func(r Resolver, _ Rejector) error {
+		resolve = r
+		return nil
+	})
+	Reject(errors.New("FAIL")).Catch(func(error) Unknown { return true })
+	Timeout(NewPromise(func(Resolver, Rejector) error { return nil }), time.Second)
+	fake.Advance(time.Second)
+
+	after := read()
+	Assert(t, after["created"]-before["created"] == 6, "Counted %d created promises, expected 6", after["created"]-before["created"])
+	Assert(t, after["rejected"]-before["rejected"] == 2, "Counted %d rejected promises, expected 2", after["rejected"]-before["rejected"])
+	Assert(t, after["resolved"]-before["resolved"] == 1, "Counted %d resolved promises, expected 1", after["resolved"]-before["resolved"])
+	Assert(t, after["timed_out"]-before["timed_out"] == 1, "Counted %d timeouts, expected 1", after["timed_out"]-before["timed_out"])
+	// the latent promise, the never settling one, and the promise Timeout derived from it
+	Assert(t, after["pending"]-before["pending"] == 3, "Counted %d pending promises, expected 3", after["pending"]-before["pending"])
+
+	resolve(nil)
+	Assert(t, read()["pending"] == after["pending"]-1, "Settling did not decrease the pending count")
+}
@@ -0,0 +1,170 @@
+package promise
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Materialize produces a Promise that always resolves with the *PromiseOutcome of the input promise.
+func Materialize(p Promise) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		p.Then(func(Unknown) Unknown {
+			return resolve(p.consumerOutcome())
+		}, func(error) Unknown {
+			return resolve(p.consumerOutcome())
+		})
+		return nil
+	})
+}
+
+// Dematerialize inverts Materialize: a resolved *PromiseOutcome with a Reason becomes a rejection.
+func Dematerialize(p Promise) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		p.Then(func(u Unknown) Unknown {
+			outcome, ok := (u).(*PromiseOutcome)
+			if !ok || outcome == nil {
+				return reject(NewPromiseError("Dematerialize requires a *PromiseOutcome value"))
+			}
+			if outcome.Reason != nil {
+				return reject(outcome.Reason)
+			}
+			return resolve(outcome.Result)
+		}, reject)
+		return nil
+	})
+}
+
+// FromOutcome inverts Outcome: it produces a Promise already settled with the status, result and reason of o,
+// and carrying its timestamps. A Pending outcome produces a promise that never settles.
+func FromOutcome(o *PromiseOutcome) Promise {
+	if o == nil {
+		return Reject(NewPromiseError("FromOutcome requires a non-nil outcome"))
+	}
+
+	prom := newPromise(traits{}, func(Resolver, Rejector) error { return nil })
+	switch o.Status {
+	case PromiseStatusName[PromiseResolved]:
+		prom.settle(PromiseResolved, o.Result, nil)
+	case PromiseStatusName[PromiseRejected]:
+		prom.settle(PromiseRejected, nil, o.Reason)
+	case PromiseStatusName[PromisePending]:
+	default:
+		return Reject(NewPromiseError(fmt.Sprintf("FromOutcome found an unknown status %q", o.Status)))
+	}
+
+	prom.mutex.Lock()
+	defer prom.mutex.Unlock()
+	if !o.CreatedAt.IsZero() {
+		prom.created = o.CreatedAt
+	}
+	if !o.SettledAt.IsZero() {
+		prom.settled = o.SettledAt
+	}
+	return prom
+}
+
+// Completed returns the outcomes that have settled, fulfilled or rejected, dropping the pending ones.
+func Completed(outcomes []*PromiseOutcome) []*PromiseOutcome {
+	return filterOutcomes(outcomes, func(o *PromiseOutcome) bool {
+		return o.Status != PromiseStatusName[PromisePending]
+	})
+}
+
+// Failures returns the outcomes that rejected.
+func Failures(outcomes []*PromiseOutcome) []*PromiseOutcome {
+	return filterOutcomes(outcomes, func(o *PromiseOutcome) bool {
+		return o.Status == PromiseStatusName[PromiseRejected]
+	})
+}
+
+func filterOutcomes(outcomes []*PromiseOutcome, keep func(*PromiseOutcome) bool) []*PromiseOutcome {
+	result := []*PromiseOutcome{}
+	for _, o := range outcomes {
+		if o != nil && keep(o) {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// BatchStats summarizes the outcomes of a batch of promises.
+// Latencies measure creation to settlement, over the settled outcomes only.
+type BatchStats struct {
+	Fulfilled int
+	Rejected  int
+	Pending   int
+
+	MinLatency  time.Duration
+	MaxLatency  time.Duration
+	MeanLatency time.Duration
+	P95Latency  time.Duration
+}
+
+// Stats aggregates outcomes, such as those AllSettled resolves with, into a BatchStats.
+// Nil outcomes are skipped.
+func Stats(outcomes []*PromiseOutcome) BatchStats {
+	stats := BatchStats{}
+	latencies := []time.Duration{}
+
+	for _, outcome := range outcomes {
+		if outcome == nil {
+			continue // like Completed and Failures
+		}
+		switch outcome.Status {
+		case PromiseStatusName[PromiseResolved]:
+			stats.Fulfilled += 1
+		case PromiseStatusName[PromiseRejected]:
+			stats.Rejected += 1
+		default:
+			stats.Pending += 1
+			continue
+		}
+		if !outcome.CreatedAt.IsZero() && !outcome.SettledAt.IsZero() {
+			latencies = append(latencies, outcome.SettledAt.Sub(outcome.CreatedAt))
+		}
+	}
+
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+
+	stats.MinLatency = latencies[0]
+	stats.MaxLatency = latencies[len(latencies)-1]
+	stats.MeanLatency = total / time.Duration(len(latencies))
+	stats.P95Latency = latencies[(len(latencies)*95+99)/100-1] // nearest rank
+	return stats
+}
+
+// Tally counts how a batch of promises settled, with the errors of the failures in input order.
+type Tally struct {
+	Succeeded int
+	Failed    int
+	Errors    []error
+}
+
+// AllComplete produces a Promise that resolves with a Tally once every input settles. It never rejects,
+// except, like AllSettled, when an input is nil.
+func AllComplete(proms ...Promise) Promise {
+	return AllSettled(proms...).Then(func(u Unknown) Unknown {
+		tally := Tally{}
+		for _, outcome := range (u).([]*PromiseOutcome) {
+			if outcome.Status == PromiseStatusName[PromiseResolved] {
+				tally.Succeeded += 1
+			} else {
+				tally.Failed += 1
+				tally.Errors = append(tally.Errors, outcome.Reason)
+			}
+		}
+		return tally
+	}, nil)
+}
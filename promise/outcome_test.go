@@ -0,0 +1,135 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPromise_Materialize(t *testing.T) {
+	fail := errors.New("FOILED!")
+
+	res, err := Materialize(Reject(fail)).Wait()
+	Assert(t, err == nil, "Materialize() rejected: %s", err)
+
+	outcome, ok := (res).(*PromiseOutcome)
+	Assert(t, ok, "Materialize() did not resolve with an outcome: %v", res)
+	Assert(t, outcome.Status == "Rejected", "Materialized outcome has unexpected status: %v", outcome.Status)
+	Assert(t, outcome.Reason == fail, "Materialized outcome has unexpected reason: %v", outcome.Reason)
+
+	res, err = Materialize(Resolve(7)).Wait()
+	outcome, _ = (res).(*PromiseOutcome)
+	Assert(t, err == nil && outcome != nil, "Materialize() failed on a resolved promise: %v, %v", res, err)
+	Assert(t, outcome.Status == "Resolved" && outcome.Result == 7, "Materialized outcome mismatch: %v", outcome)
+}
+
+func TestPromise_Dematerialize(t *testing.T) {
+	fail := errors.New("FOILED!")
+
+	_, err := Dematerialize(Materialize(Reject(fail))).Wait()
+	Assert(t, err == fail, "Dematerialize() did not restore the rejection: %v", err)
+
+	res, err := Dematerialize(Materialize(Resolve(7))).Wait()
+	Assert(t, err == nil && res == 7, "Dematerialize() did not restore the result: %v, %v", res, err)
+
+	_, err = Dematerialize(Resolve(7)).Wait()
+	Assert(t, err != nil, "Dematerialize() accepted a value that is not an outcome")
+}
+
+func TestPromise_FromOutcome(t *testing.T) {
+	fail := errors.New("FOILED!")
+
+	for _, prom := range []Promise{Resolve(7), Reject(fail), NewPromise(func(Resolver, Rejector) error { return nil })} {
+		prom.Catch(func(error) Unknown { return nil })
+		original := prom.Outcome()
+		restored := FromOutcome(original).Outcome()
+		Assert(t, *restored == *original, "FromOutcome() did not round-trip: %v became %v", original, restored)
+	}
+
+	res, err := FromOutcome(Resolve(7).Outcome()).Wait()
+	Assert(t, err == nil && res == 7, "FromOutcome() promise did not resolve: %v, %v", res, err)
+
+	_, err = FromOutcome(&PromiseOutcome{Status: "Unknown"}).Wait()
+	Assert(t, err != nil, "FromOutcome() accepted an unknown status")
+}
+
+func TestCompleted(t *testing.T) {
+	pending := &PromiseOutcome{Status: "Pending"}
+	resolved := &PromiseOutcome{Status: "Resolved", Result: 1}
+	rejected := &PromiseOutcome{Status: "Rejected", Reason: errors.New("FAIL")}
+
+	for _, c := range []struct {
+		name      string
+		outcomes  []*PromiseOutcome
+		completed []*PromiseOutcome
+		failures  []*PromiseOutcome
+	}{
+		{"empty", nil, nil, nil},
+		{"pending only", []*PromiseOutcome{pending, pending}, nil, nil},
+		{"settled only", []*PromiseOutcome{resolved, rejected}, []*PromiseOutcome{resolved, rejected}, []*PromiseOutcome{rejected}},
+		{"mixed", []*PromiseOutcome{rejected, pending, resolved, nil, rejected},
+			[]*PromiseOutcome{rejected, resolved, rejected}, []*PromiseOutcome{rejected, rejected}},
+	} {
+		completed, failures := Completed(c.outcomes), Failures(c.outcomes)
+		Assert(t, equalOutcomes(completed, c.completed), "%s: Completed() returned %v, expected %v", c.name, completed, c.completed)
+		Assert(t, equalOutcomes(failures, c.failures), "%s: Failures() returned %v, expected %v", c.name, failures, c.failures)
+	}
+}
+
+func equalOutcomes(a, b []*PromiseOutcome) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStats(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	outcome := func(status string, latency time.Duration) *PromiseOutcome {
+		return &PromiseOutcome{Status: status, CreatedAt: start, SettledAt: start.Add(latency)}
+	}
+
+	outcomes := []*PromiseOutcome{
+		{Status: "Pending", CreatedAt: start},
+		outcome("Rejected", 40*time.Millisecond),
+	}
+	for i := 1; i <= 19; i++ {
+		outcomes = append(outcomes, outcome("Resolved", time.Duration(i)*time.Millisecond))
+	}
+
+	stats := Stats(outcomes)
+	Assert(t, stats.Fulfilled == 19, "Stats() counted %d fulfilled, expected 19", stats.Fulfilled)
+	Assert(t, stats.Rejected == 1, "Stats() counted %d rejected, expected 1", stats.Rejected)
+	Assert(t, stats.Pending == 1, "Stats() counted %d pending, expected 1", stats.Pending)
+	Assert(t, stats.MinLatency == time.Millisecond, "Stats() has unexpected minimum latency: %s", stats.MinLatency)
+	Assert(t, stats.MaxLatency == 40*time.Millisecond, "Stats() has unexpected maximum latency: %s", stats.MaxLatency)
+	Assert(t, stats.MeanLatency == 11500*time.Microsecond, "Stats() has unexpected mean latency: %s", stats.MeanLatency)
+	Assert(t, stats.P95Latency == 19*time.Millisecond, "Stats() has unexpected p95 latency: %s", stats.P95Latency)
+
+	empty := Stats(nil)
+	Assert(t, empty == BatchStats{}, "Stats() of no outcomes is not empty: %v", empty)
+
+	withNil := Stats([]*PromiseOutcome{nil, outcome("Resolved", time.Millisecond), nil})
+	Assert(t, withNil.Fulfilled == 1 && withNil.Pending == 0, "Stats() miscounted nil outcomes: %v", withNil)
+
+	res, _ := AllSettled(Resolve(1), Reject(errors.New("FAIL"))).Wait()
+	stats = Stats((res).([]*PromiseOutcome))
+	Assert(t, stats.Fulfilled == 1 && stats.Rejected == 1, "Stats() miscounted settled promises: %v", stats)
+}
+
+func TestAllComplete(t *testing.T) {
+	first, second := errors.New("first"), errors.New("second")
+	res, err := AllComplete(Resolve(1), Reject(first), Resolve(2), RejectAfter(time.Millisecond, second), Resolve(3)).Wait()
+	tally := (res).(Tally)
+	Assert(t, err == nil, "AllComplete() rejected: %v", err)
+	Assert(t, tally.Succeeded == 3 && tally.Failed == 2, "AllComplete() miscounted: %v", tally)
+	Assert(t, len(tally.Errors) == 2 && tally.Errors[0] == first && tally.Errors[1] == second, "AllComplete() has unexpected errors: %v", tally.Errors)
+
+	res, _ = AllComplete().Wait()
+	Assert(t, (res).(Tally).Succeeded == 0 && (res).(Tally).Errors == nil, "AllComplete() of nothing is not empty: %v", res)
+}
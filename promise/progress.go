@@ -0,0 +1,171 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// ProgressPromise is a Promise that reports progress while it is pending.
+type ProgressPromise interface {
+	Promise
+	// OnProgress registers fn to receive each progress value reported until the promise settles.
+	OnProgress(fn func(float64))
+}
+
+// ProgressHandler is a PromiseHandler that can also report progress.
+type ProgressHandler func(resolve Resolver, reject Rejector, progress func(float64)) error
+
+type progressPromise struct {
+	*aPromise
+	progressMu   sync.Mutex
+	listeners    []func(float64)
+	progressDone bool
+}
+
+// NewProgressPromise creates a promise like NewPromise, whose handler also receives a function to report
+// progress with, conventionally a fraction between 0 and 1. Listeners run on the reporting goroutine, in
+// registration order, so they should be quick. Progress reported after the promise settles is dropped.
+func NewProgressPromise(handler ProgressHandler) ProgressPromise {
+	prom := &progressPromise{}
+	prom.aPromise = newPromise(traits{}, func(resolve Resolver, reject Rejector) error {
+		return handler(resolve, reject, prom.report)
+	})
+
+	prom.mutex.Lock()
+	defer prom.mutex.Unlock()
+	if prom.status != PromisePending {
+		prom.done()
+	} else {
+		prom.hooks = append(prom.hooks, prom.done)
+	}
+	return prom
+}
+
+func (p *progressPromise) OnProgress(fn func(float64)) {
+	p.progressMu.Lock()
+	defer p.progressMu.Unlock()
+	if !p.progressDone {
+		p.listeners = append(p.listeners, fn)
+	}
+}
+
+func (p *progressPromise) report(progress float64) {
+	p.progressMu.Lock()
+	listeners := p.listeners
+	if p.progressDone {
+		listeners = nil
+	}
+	p.progressMu.Unlock()
+
+	for _, fn := range listeners {
+		fn(progress)
+	}
+}
+
+// done releases the listeners once the promise settles.
+func (p *progressPromise) done() {
+	p.progressMu.Lock()
+	defer p.progressMu.Unlock()
+	p.progressDone = true
+	p.listeners = nil
+}
+
+// IdleTimeout produces a Promise that settles like p, unless a period of d passes without p reporting
+// progress, in which case it rejects with ErrDeadlineExceeded. Each progress event restarts the period.
+func IdleTimeout(d time.Duration, p ProgressPromise) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		var mutex sync.Mutex
+		expire := func() {
+			counters.timedOut.Add(1)
+			reject(ErrDeadlineExceeded)
+		}
+		timer := afterFunc(d, expire)
+
+		p.OnProgress(func(float64) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if timer.Stop() {
+				timer = afterFunc(d, expire)
+			}
+		})
+
+		stop := func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+			timer.Stop()
+		}
+		p.Then(func(u Unknown) Unknown {
+			stop()
+			return resolve(u)
+		}, func(err error) Unknown {
+			stop()
+			return reject(err)
+		})
+		return nil
+	})
+}
+
+// AggregateProgress joins proms like All, and reports their overall progress, the mean of the progress of each,
+// on the channel. A child counts as 0 until it first reports, and as 1 once it resolves. The channel holds
+// only the latest value, so a slow reader skips intermediate ones and reporting never blocks; it receives 1
+// once all the children resolve, and is closed before the promise settles.
+func AggregateProgress(proms ...ProgressPromise) (Promise, <-chan float64) {
+	values := make(chan float64, 1)
+	var mutex sync.Mutex
+	progress := make([]float64, len(proms))
+	closed := false
+
+	// update records value for child i and sends the new mean, replacing one not yet received.
+	update := func(i int, value float64) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if closed {
+			return
+		}
+		progress[i] = value
+		total := 0.0
+		for _, p := range progress {
+			total += p
+		}
+		select {
+		case <-values: // the reader has not caught up
+		default:
+		}
+		values <- total / float64(len(progress))
+	}
+
+	inputs := make([]Promise, len(proms))
+	for i, p := range proms {
+		i := i
+		inputs[i] = p
+		p.OnProgress(func(value float64) {
+			update(i, value)
+		})
+		p.subscribe(func(status uint, _ Unknown, _ error) {
+			if status == PromiseResolved {
+				update(i, 1)
+			}
+		})
+	}
+
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		All(inputs...).subscribe(func(status uint, val Unknown, err error) {
+			if status == PromiseResolved {
+				for i := range proms {
+					update(i, 1) // the children's own updates may not have run yet
+				}
+			}
+			mutex.Lock()
+			closed = true
+			close(values)
+			mutex.Unlock()
+
+			if status == PromiseResolved {
+				resolve(val)
+			} else {
+				reject(err)
+			}
+		})
+		return nil
+	}), values
+}
@@ -0,0 +1,101 @@
+package promise
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewProgressPromise(t *testing.T) {
+	var resolve Resolver
+	var progress func(float64)
+	prom := NewProgressPromise(func(r Resolver, _ Rejector, p func(float64)) error {
+		resolve, progress = r, p
+		return nil
+	})
+
+	reported := []float64{}
+	prom.OnProgress(func(f float64) {
+		reported = append(reported, f)
+	})
+
+	progress(0.5)
+	progress(1)
+	resolve("done")
+	progress(2)
+
+	res, err := prom.Wait()
+	Assert(t, err == nil && res == "done", "Progress promise produced an unexpected outcome: %v, %v", res, err)
+	Assert(t, len(reported) == 2 && reported[0] == 0.5 && reported[1] == 1, "Unexpected progress reported: %v", reported)
+}
+
+func TestIdleTimeout(t *testing.T) {
+	fake := useFakeClock(t)
+
+	var resolve Resolver
+	var progress func(float64)
+	latent := func() ProgressPromise {
+		return NewProgressPromise(func(r Resolver, _ Rejector, p func(float64)) error {
+			resolve, progress = r, p
+			return nil
+		})
+	}
+
+	// steady progress keeps the promise alive past the idle period
+	prom := IdleTimeout(10*time.Millisecond, latent())
+	for i := 0; i < 5; i++ {
+		fake.Advance(9 * time.Millisecond)
+		progress(float64(i) / 5)
+	}
+	Assert(t, prom.GetStatus() == "Pending", "IdleTimeout() expired despite progress, found %v", prom.GetStatus())
+	resolve("done")
+
+	res, err := prom.Wait()
+	Assert(t, err == nil && res == "done", "IdleTimeout() did not settle like its input: %v, %v", res, err)
+	Assert(t, fake.Pending() == 0, "IdleTimeout() left %d timers scheduled", fake.Pending())
+
+	// a stall rejects once the idle period passes
+	prom = IdleTimeout(10*time.Millisecond, latent())
+	fake.Advance(5 * time.Millisecond)
+	progress(0.1)
+	fake.Advance(10 * time.Millisecond)
+
+	_, err = prom.Wait()
+	Assert(t, err == ErrDeadlineExceeded, "IdleTimeout() did not reject a stalled promise: %v", err)
+}
+
+func TestAggregateProgress(t *testing.T) {
+	useFakeClock(t) // for the inline executor
+
+	resolvers := []Resolver{}
+	var progress func(float64)
+	children := []ProgressPromise{}
+	for i := 0; i < 2; i++ {
+		children = append(children, NewProgressPromise(func(r Resolver, _ Rejector, p func(float64)) error {
+			resolvers = append(resolvers, r)
+			if progress == nil {
+				progress = p // only the first child reports
+			}
+			return nil
+		}))
+	}
+
+	prom, values := AggregateProgress(children...)
+	progress(0.5)
+	Assert(t, <-values == 0.25, "AggregateProgress() did not report the mean")
+
+	progress(0.6)
+	progress(0.8)
+	Assert(t, <-values == 0.4, "AggregateProgress() did not keep only the latest value")
+
+	resolvers[1]("second")
+	Assert(t, <-values == 0.9, "AggregateProgress() did not count a resolved child as done")
+	Assert(t, prom.GetStatus() == "Pending", "AggregateProgress() settled with a child pending")
+
+	resolvers[0]("first")
+	Assert(t, <-values == 1.0, "AggregateProgress() did not report completion")
+	_, open := <-values
+	Assert(t, !open, "AggregateProgress() did not close the channel")
+
+	res, err := prom.Wait()
+	Assert(t, err == nil && (res).([]Unknown)[0] == "first", "AggregateProgress() did not resolve like All: %v, %v", res, err)
+}
@@ -1,8 +1,14 @@
 package promise
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Unknown interface{}
@@ -23,6 +29,8 @@ var (
 
 type PromiseError struct {
 	description string
+	lazy        func() string // formats description on first use, see NewLazyPromiseError
+	once        sync.Once
 }
 
 type MultiPromiseError struct {
@@ -31,6 +39,9 @@ type MultiPromiseError struct {
 }
 
 func (e *PromiseError) Error() string {
+	if e.lazy != nil {
+		e.once.Do(func() { e.description = e.lazy() })
+	}
 	return fmt.Sprintf("Promise error: %s", e.description)
 }
 
@@ -40,14 +51,103 @@ func NewPromiseError(text string) *PromiseError {
 	}
 }
 
+// NewLazyPromiseError is NewPromiseError for messages that are costly to format: format runs once, the first
+// time Error is called, so rejections that are never read cost nothing to describe.
+func NewLazyPromiseError(format func() string) *PromiseError {
+	return &PromiseError{
+		lazy: format,
+	}
+}
+
+// Error reports how many of the promises failed, and the index and reason of each failure, as in
+// "all promises failed: 2 of 3 failed [1: error one; 2: error two]". When more than groupFailuresAbove
+// promises failed and reasons repeat, it reports each distinct message once with its count instead, most
+// frequent first, as in "all promises failed: 500 of 500 failed [connection refused (×487); timeout (×13)]".
 func (e *MultiPromiseError) Error() string {
 	message := []string{}
-	for _, e := range e.Outcomes() {
-		if e.Reason != nil {
-			message = append(message, e.Reason.Error())
+	for i, o := range e.Outcomes() {
+		if o.Reason != nil {
+			message = append(message, fmt.Sprintf("%d: %s", i, o.Reason.Error()))
+		}
+	}
+	failed := len(message)
+
+	if groups := e.GroupByMessage(); failed > groupFailuresAbove && len(groups) < failed {
+		message = message[:0]
+		for text := range groups {
+			message = append(message, text)
+		}
+		sort.Slice(message, func(i, j int) bool {
+			if groups[message[i]] != groups[message[j]] {
+				return groups[message[i]] > groups[message[j]]
+			}
+			return message[i] < message[j]
+		})
+		for i, text := range message {
+			if groups[text] > 1 {
+				message[i] = fmt.Sprintf("%s (×%d)", text, groups[text])
+			}
 		}
 	}
-	return fmt.Sprintf("%s: %s", e.Description, strings.Join(message, "; "))
+	return fmt.Sprintf("%s: %d of %d failed [%s]", e.Description, failed, len(e.Promises), strings.Join(message, "; "))
+}
+
+// groupFailuresAbove is the number of failures up to which MultiPromiseError lists each one with its index.
+const groupFailuresAbove = 10
+
+// GroupByMessage counts the failed promises by the message of their rejection reason.
+func (e *MultiPromiseError) GroupByMessage() map[string]int {
+	groups := map[string]int{}
+	for _, err := range e.Errors() {
+		groups[err.Error()] += 1
+	}
+	return groups
+}
+
+// Errors returns the rejection reasons of the failed promises, in input order.
+func (e *MultiPromiseError) Errors() []error {
+	errs := []error{}
+	for _, o := range e.Outcomes() {
+		if o.Reason != nil {
+			errs = append(errs, o.Reason)
+		}
+	}
+	return errs
+}
+
+// Unwrap exposes the rejection reasons to errors.Is and errors.As.
+func (e *MultiPromiseError) Unwrap() []error {
+	return e.Errors()
+}
+
+// IndexedError identifies which of several input promises caused a rejection.
+type IndexedError struct {
+	Index int
+	Err   error
+}
+
+func (e *IndexedError) Error() string {
+	return fmt.Sprintf("promise %d: %s", e.Index, e.Err.Error())
+}
+
+func (e *IndexedError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is the rejection of a promise whose handler panicked, carrying the recovered value and the stack
+// of the panicking goroutine. It unwraps to the recovered value when that is an error.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("Promise error: panic: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := (e.Value).(error)
+	return err
 }
 
 func NewMultiPromiseError(description string, promises []Promise) *MultiPromiseError {
@@ -75,32 +175,159 @@ type Thenable interface {
 
 type Promise interface {
 	Then(Resolver, Rejector) Promise
+	OnResolved(Resolver) Promise
+	ThenRetry(int, Resolver) Promise
+	ThenPromise(func(Unknown) (Promise, error)) Promise
+	ThenRaw(Resolver) Promise
 	Catch(Rejector) Promise
+	CatchOutcome(func(*PromiseOutcome) Unknown) Promise
+	OrElse(func(error) Promise) Promise
+	OrValue(Unknown) Promise
+	OrValueFunc(func(error) Unknown) Promise
+	Recover(func(error) (Unknown, error)) Promise
+	As(target interface{}) Promise
 	GetStatus() string
 	Channel() (<-chan Unknown, <-chan error)
 	Wait() (Unknown, error)
+	WaitOutcome() *PromiseOutcome
+	WaitContext(context.Context) (Unknown, error)
+	Func() func() (Unknown, error)
+	FuncContext(context.Context) func() (Unknown, error)
+	Deadline(time.Time) Promise
 	makeError(string) error
+	subscribe(func(uint, Unknown, error))
+	consumerOutcome() *PromiseOutcome
 	Outcome() *PromiseOutcome
+	PendingCallbacks() int
+	Parent() Promise
+	WithValue(key, val interface{}) Promise
+	Value(key interface{}) interface{}
+	WithName(string) Promise
 }
 
 type aPromise struct {
+	mutex     sync.Mutex
 	status    uint
 	result    Unknown
 	reject    error
 	callbacks []func(uint, Unknown, error)
+	unhandled bool // a finalizer reports the rejection unless an observer attaches
+	created   time.Time
+	settled   time.Time
+	hooks     []func() // run once, when the promise settles
+	copier    Copier   // set by Freeze, to copy the result for each consumer
+	traits
+}
+
+// traits is the state a derived promise inherits from the promise it was derived from.
+type traits struct {
+	token    Token
+	executor Executor  // nil dispatches through the executor installed by SetExecutor
+	parent   *aPromise // recorded only with TrackLineage
+	values   *valueNode
+}
+
+// valueNode is one entry of the immutable list of values a promise carries, most recent first.
+type valueNode struct {
+	next     *valueNode
+	key, val interface{}
+}
+
+// derive returns the traits of a promise derived from p.
+func (p *aPromise) derive() traits {
+	inherit := p.traits
+	inherit.parent = nil
+	if TrackLineage {
+		inherit.parent = p
+	}
+	return inherit
+}
+
+// WithValue derives a Promise that settles like p and carries val under key, in addition to the values p
+// carries. Values are copied on derive, like context.WithValue: promises derived from the result see val,
+// while p and promises derived from p earlier do not.
+func (p *aPromise) WithValue(key, val interface{}) Promise {
+	inherit := p.derive()
+	inherit.values = &valueNode{next: p.values, key: key, val: val}
+	return newPromise(inherit, func(resolve Resolver, reject Rejector) error {
+		p.subscribe(func(status uint, val Unknown, err error) {
+			if status == PromiseResolved {
+				resolve(val)
+			} else {
+				reject(err)
+			}
+		})
+		return nil
+	})
+}
+
+// Value returns the value p carries under key, from the most recent WithValue along its chain, or nil.
+func (p *aPromise) Value(key interface{}) interface{} {
+	for node := p.values; node != nil; node = node.next {
+		if node.key == key {
+			return node.val
+		}
+	}
+	return nil
+}
+
+// Parent returns the promise p was derived from by Then or a method built on it, or nil if p is a root or
+// TrackLineage was off when p was derived.
+func (p *aPromise) Parent() Promise {
+	if p.parent == nil {
+		return nil
+	}
+	return p.parent
 }
 
 func (p *aPromise) Outcome() *PromiseOutcome {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	return &PromiseOutcome{
-		Status: p.GetStatus(),
-		Result: p.result,
-		Reason: p.reject,
+		Status:    PromiseStatusName[p.status],
+		Result:    p.result,
+		Reason:    p.reject,
+		CreatedAt: p.created,
+		SettledAt: p.settled,
+	}
+}
+
+// PendingCallbacks reports how many callbacks are waiting for p to settle. It is a diagnostic: a count that
+// keeps growing on a long-lived pending promise points to a leak of derived promises or waiters.
+func (p *aPromise) PendingCallbacks() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.callbacks)
+}
+
+// ErrNilPromise is wrapped by the rejection of a combinator that was given a nil promise.
+var ErrNilPromise = NewPromiseError("nil promise")
+
+// checkPromises reports the first nil input of a combinator as an *IndexedError wrapping ErrNilPromise.
+func checkPromises(proms []Promise) error {
+	for i, p := range proms {
+		if isNilPromise(p) {
+			return &IndexedError{Index: i, Err: ErrNilPromise}
+		}
 	}
+	return nil
+}
+
+// isNilPromise reports whether p is nil, or a nil pointer wrapped in the Promise interface.
+func isNilPromise(p Promise) bool {
+	if p == nil {
+		return true
+	}
+	value := reflect.ValueOf(p)
+	return value.Kind() == reflect.Ptr && value.IsNil()
 }
 
 // Race produces a Promise that will resolve or reject with the value of the first the input promise that resolves or rejects.
 func Race(proms ...Promise) Promise {
 	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		if err := checkPromises(proms); err != nil {
+			return err
+		}
 		for _, p := range proms {
 			p.Then(resolve, reject)
 		}
@@ -108,90 +335,300 @@ func Race(proms ...Promise) Promise {
 	})
 }
 
+// IndexedValue is a result together with the index of the input promise that produced it.
+type IndexedValue struct {
+	Index int
+	Value Unknown
+}
+
+// RaceIndex is Race, reporting which input settled first: it resolves with an IndexedValue, or rejects with
+// an *IndexedError.
+func RaceIndex(proms ...Promise) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		if err := checkPromises(proms); err != nil {
+			return err
+		}
+		for i, p := range proms {
+			func(index int, prom Promise) {
+				prom.Then(func(u Unknown) Unknown {
+					return resolve(IndexedValue{Index: index, Value: u})
+				}, func(e error) Unknown {
+					return reject(&IndexedError{Index: index, Err: e})
+				})
+			}(i, p)
+		}
+		return nil
+	})
+}
+
 // All produces a Promise that resovles with the results of _all_ the input promises.
 func All(proms ...Promise) Promise {
 	return NewPromise(func(resolve Resolver, reject Rejector) error {
-
-		count := len(proms)
-		count_success := 0
-		_results := make([]Unknown, count)
-
-		make_resolver := func(i int, p Promise, resolve Resolver) Resolver {
-			return func(u Unknown) Unknown {
-				_results[i] = u
-				count_success += 1
-				if count == count_success {
-					return resolve(_results)
-				}
-				return nil
-			}
+		if err := checkPromises(proms); err != nil {
+			return err
 		}
+		combiner := NewCombiner(len(proms))
+		combiner.OnDone(func() {
+			resolve(combiner.Results())
+		})
 
 		// register on each incoming promise
 		for i, p := range proms {
-			p.Then(make_resolver(i, p, resolve), reject)
+			i := i
+			p.Then(func(u Unknown) Unknown {
+				combiner.Resolve(i, u)
+				return nil
+			}, reject)
 		}
-
 		return nil
 	})
 }
 
+// AllIndexed is All, rejecting with an *IndexedError that wraps the error of the first input to reject and
+// identifies it by position.
+func AllIndexed(proms ...Promise) Promise {
+	indexed := make([]Promise, len(proms))
+	for i, p := range proms {
+		if isNilPromise(p) {
+			continue // All reports it
+		}
+		i := i
+		indexed[i] = p.Catch(func(e error) Unknown {
+			return &IndexedError{Index: i, Err: e}
+		})
+	}
+	return All(indexed...)
+}
+
+// AllWaitFail is All without the fail-fast: it resolves with the results of all the input promises, but on any
+// rejection it still waits for every promise to settle, and then rejects with a *MultiPromiseError carrying
+// all their outcomes. Use it when the promises must finish, e.g. to release resources, before the caller moves on.
+func AllWaitFail(proms ...Promise) Promise {
+	return AllSettled(proms...).OnResolved(func(u Unknown) Unknown {
+		outcomes := (u).([]*PromiseOutcome)
+		results := make([]Unknown, len(outcomes))
+		for i, o := range outcomes {
+			if o.Reason != nil {
+				return NewMultiPromiseError("not all promises fulfilled", proms)
+			}
+			results[i] = o.Result
+		}
+		return results
+	})
+}
+
 // Any produces a Promise that resolves with the first input promise that fulfills (not account for rejections).
+// With no inputs it rejects at once, since none can fulfill.
 func Any(proms ...Promise) Promise {
 	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		if err := checkPromises(proms); err != nil {
+			return err
+		}
+		combiner := NewCombiner(len(proms))
+		combiner.OnDone(func() {
+			reject(NewMultiPromiseError("all promises failed", proms))
+		})
+
+		for i, prom := range proms {
+			i := i
+			prom.Then(resolve, func(e error) Unknown {
+				combiner.Reject(i, e)
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+// Quorum produces a Promise that resolves once k of the input promises fulfill, with their values in the order
+// they fulfilled. It rejects with a *MultiPromiseError as soon as so many inputs have failed that k can no
+// longer fulfill, without waiting for the rest.
+func Quorum(k int, proms ...Promise) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		if err := checkPromises(proms); err != nil {
+			return err
+		}
+		if k > len(proms) {
+			return NewPromiseError(fmt.Sprintf("quorum of %d is larger than %d promises", k, len(proms)))
+		}
+		if k <= 0 {
+			resolve([]Unknown{})
+			return nil
+		}
+
+		var mutex sync.Mutex // the callbacks run concurrently
+		values := make([]Unknown, 0, k)
 		failures := 0
-		total := len(proms)
-		for index, prom := range proms {
-			func(i int, prom Promise) {
-				prom.Then(resolve, func(e error) Unknown {
-					failures += 1
-					if failures == total {
-						reject(NewMultiPromiseError("all promises failed", proms))
-					}
+		settled := false
+
+		for _, prom := range proms {
+			prom.Then(func(u Unknown) Unknown {
+				mutex.Lock()
+				defer mutex.Unlock()
+				if settled {
 					return nil
-				})
-			}(index, prom)
+				}
+				values = append(values, u)
+				if len(values) == k {
+					settled = true
+					resolve(values)
+				}
+				return nil
+			}, func(e error) Unknown {
+				mutex.Lock()
+				defer mutex.Unlock()
+				if settled {
+					return nil
+				}
+				failures += 1
+				if failures > len(proms)-k {
+					settled = true
+					reject(NewMultiPromiseError("quorum not reachable", proms))
+				}
+				return nil
+			})
 		}
 		return nil
 	})
 }
 
 type PromiseOutcome struct {
-	Status string
-	Result Unknown
-	Reason error
+	Status    string
+	Result    Unknown
+	Reason    error
+	CreatedAt time.Time
+	SettledAt time.Time // zero while pending
 }
 
 // AllSettled produces a promise which resolves when all input promises are settled (fulfilled or rejected).
 func AllSettled(proms ...Promise) Promise {
-
 	return NewPromise(func(resolve Resolver, reject Rejector) error {
-		total := len(proms)
-		settled := 0
+		if err := checkPromises(proms); err != nil {
+			return err
+		}
+		combiner := NewCombiner(len(proms))
+
+		// Each input has settled by the time it is recorded, so the outcomes are complete once all are.
+		combiner.OnDone(func() {
+			resolve(getPromiseOutcomes(proms))
+		})
 
 		for i, p := range proms {
-			func(index int, prom Promise) {
-				prom.Then(func(u Unknown) Unknown {
-					settled += 1
-					if settled == total {
-						resolve(getPromiseOutcomes(proms))
-					}
-					return nil
-				}, func(e error) Unknown {
-					settled += 1
-					if settled == total {
-						resolve(getPromiseOutcomes(proms))
-					}
-					return nil
-				})
-			}(i, p)
+			i := i
+			p.Then(func(u Unknown) Unknown {
+				combiner.Resolve(i, u)
+				return nil
+			}, func(e error) Unknown {
+				combiner.Reject(i, e)
+				return nil
+			})
 		}
 		return nil
 	})
 }
 
+// AllKeyedSettled produces a Promise which resolves with a map of outcomes once every promise in the input map is settled.
+func AllKeyedSettled(m map[string]Promise) Promise {
+	keys := make([]string, 0, len(m))
+	proms := make([]Promise, 0, len(m))
+	for key, prom := range m {
+		keys = append(keys, key)
+		proms = append(proms, prom)
+	}
+
+	return AllSettled(proms...).OnResolved(func(u Unknown) Unknown {
+		result := make(map[string]*PromiseOutcome, len(keys))
+		for i, outcome := range (u).([]*PromiseOutcome) {
+			result[keys[i]] = outcome
+		}
+		return result
+	})
+}
+
+// FilterFulfilled produces a Promise that resolves with the values of the input promises that fulfilled, in input order.
+// Rejected inputs are ignored, so it never rejects; callers needing at least one success should check the length.
+func FilterFulfilled(proms ...Promise) Promise {
+	return AllSettled(proms...).OnResolved(func(u Unknown) Unknown {
+		values := []Unknown{}
+		for _, outcome := range (u).([]*PromiseOutcome) {
+			if outcome.Status == PromiseStatusName[PromiseResolved] {
+				values = append(values, outcome.Result)
+			}
+		}
+		return values
+	})
+}
+
 // Resolve produces a Promise that is immediately resolved with the input value.
+//
+// Resolving with nil, a bool, 0, "" or Void returns a shared promise rather than allocating a new one.
+// This is safe because a settled promise never changes: Then on it dispatches the callback
+// without retaining it, so consumers of a shared promise cannot observe each other.
+// Callers must therefore not rely on the identity of promises resolved with these values. Nor do their
+// outcomes carry timestamps, which would only date the process: CreatedAt and SettledAt are zero, and Stats
+// leaves them out of its latencies.
 func Resolve(val Unknown) Promise {
+	if prom := cachedResolution(val); prom != nil {
+		return prom
+	}
+	return newResolved(val)
+}
+
+// ResolveRaw produces a Promise resolved with val verbatim. Resolve, like the resolver of NewPromise, adopts
+// a Thenable val and resolves with its result instead; ResolveRaw resolves with the Thenable itself, so a
+// consumer receives the promise object, e.g. to store it.
+func ResolveRaw(val Unknown) Promise {
+	prom := newPromise(traits{}, func(Resolver, Rejector) error { return nil })
+	prom.settle(PromiseResolved, val, nil)
+	return prom
+}
+
+// ResolvedNil returns the shared Promise resolved with nil.
+func ResolvedNil() Promise {
+	return resolvedConstants[nil]
+}
+
+type void struct{}
+
+func (void) String() string {
+	return "<void>"
+}
+
+// Void is a result that means "resolved with no value". Resolving with nil settles a promise just as well,
+// but nil is also what many code paths produce by accident, or may mean something to the consumer; a
+// consumer can compare a result with Void to tell a deliberate lack of value apart.
+var Void Unknown = void{}
+
+// ResolveVoid returns a shared Promise resolved with Void.
+func ResolveVoid() Promise {
+	return resolvedConstants[Void]
+}
+
+var resolvedConstants = map[Unknown]Promise{
+	nil:   newShared(nil),
+	true:  newShared(true),
+	false: newShared(false),
+	0:     newShared(0),
+	"":    newShared(""),
+	Void:  newShared(Void),
+}
+
+// newShared is newResolved without the timestamps, for the promises Resolve shares between callers.
+func newShared(val Unknown) Promise {
+	prom := newResolved(val).(*aPromise)
+	prom.created, prom.settled = time.Time{}, time.Time{}
+	return prom
+}
+
+func cachedResolution(val Unknown) Promise {
+	switch val.(type) {
+	case nil, bool, int, string, void: // only hashable types may be looked up
+		return resolvedConstants[val]
+	}
+	return nil
+}
+
+func newResolved(val Unknown) Promise {
 	return NewPromise(func(r1 Resolver, r2 Rejector) error {
 		r1(val)
 		return nil
@@ -209,28 +646,44 @@ func (p *aPromise) makeError(message string) error {
 	return NewPromiseError(fmt.Sprintf("%s (status: %s)", message, p.GetStatus()))
 }
 
+// Then derives a Promise settled by onsuccess or onfail, whichever matches how p settles. Either handler may
+// return an error to reject the derived promise, a Promise to chain on it, a <-chan Unknown or a ChannelPair
+// to adopt the first value received through FromChannel, a []Promise to join with All, or any other value to
+// resolve with it. Without a handler for how p settled, the derived promise settles with p's exact result or
+// error, so errors.Is and errors.As find the original at the end of any chain. ThenRaw resolves with what its
+// handler returns as is.
+//
+// Passing both handlers registers a single callback on p and derives a single promise. That is not the same
+// as Then(onsuccess, nil).Catch(onfail), which takes an extra hop and also catches errors from onsuccess.
 func (p *aPromise) Then(onsuccess Resolver, onfail Rejector) Promise {
-	return NewPromise(func(resolve Resolver, reject Rejector) error {
+	return newPromise(p.derive(), func(resolve Resolver, reject Rejector) error {
 
 		handle := func(status uint, val Unknown, err error) {
 
-			// Handles error's from handlers panicing.
+			if p.token != nil && p.token.Canceled() {
+				reject(ErrCanceled) // the chain was aborted, so skip the handlers
+				return
+			}
+
+			// Handles panics in the handlers.
 			defer func() {
-				r := recover()
-				err, ok := (r).(error)
-				if ok && (err != nil) {
-					reject(err)
+				if r := recover(); r != nil {
+					reject(recoveredError(r))
 				}
 			}()
 
 			switch status {
 			case PromiseRejected: // the parent promise failed
 				if onfail != nil {
-					res := onfail(err)
+					res := adopt(onfail(err))
 					err, ok := (res).(error)
 					if ok && (err != nil) {
 						reject(err)
 					}
+					if prom, ok := res.(Promise); ok && isNilPromise(prom) {
+						reject(ErrNilPromise) // a typed nil would panic on Then
+						return
+					}
 					if res != nil {
 						resolve(res) // non-error returns from rejection are treated as recovery
 					}
@@ -241,7 +694,7 @@ func (p *aPromise) Then(onsuccess Resolver, onfail Rejector) Promise {
 
 			case PromiseResolved: // the parent promise fulfilled
 				if onsuccess != nil {
-					res := onsuccess(val)
+					res := adopt(onsuccess(val))
 
 					err, ok := res.(error)
 					if ok && (err != nil) {
@@ -249,6 +702,10 @@ func (p *aPromise) Then(onsuccess Resolver, onfail Rejector) Promise {
 					}
 
 					prom, ok := res.(Promise)
+					if ok && isNilPromise(prom) {
+						reject(ErrNilPromise) // a typed nil would panic on Then
+						return
+					}
 					if ok && (prom != nil) {
 						prom.Then(resolve, reject)
 					}
@@ -263,112 +720,406 @@ func (p *aPromise) Then(onsuccess Resolver, onfail Rejector) Promise {
 			}
 		}
 
-		switch p.status {
-		case PromisePending:
-			// Enqueue the promise for pending values
-			p.callbacks = append(p.callbacks, handle)
-		default:
-			// Execute the promise with existing values
-			go handle(p.status, p.result, p.reject)
+		p.subscribe(handle)
+		return nil
+	})
+}
+
+// ThenRaw derives a Promise resolved verbatim with what onsuccess returns, without the interpretation Then
+// applies: a returned Promise, channel or error is the result itself, rather than adopted or rejected with.
+// A panic in onsuccess, and a rejection of p, reject the derived promise as with Then.
+func (p *aPromise) ThenRaw(onsuccess Resolver) Promise {
+	derived := newPromise(p.derive(), func(Resolver, Rejector) error { return nil })
+	p.subscribe(func(status uint, val Unknown, err error) {
+		if p.token != nil && p.token.Canceled() {
+			derived.settle(PromiseRejected, nil, ErrCanceled)
+			return
+		}
+		if status != PromiseResolved {
+			derived.settle(PromiseRejected, nil, err)
+			return
 		}
 
+		defer func() {
+			if r := recover(); r != nil {
+				derived.settle(PromiseRejected, nil, recoveredError(r))
+			}
+		}()
+		derived.settle(PromiseResolved, onsuccess(val), nil)
+	})
+	return derived
+}
+
+// subscribe registers handle to receive the outcome of p once it settles.
+func (p *aPromise) subscribe(handle func(uint, Unknown, error)) {
+	if p.copier != nil {
+		handle = p.copying(handle)
+	}
+
+	p.mutex.Lock()
+	if p.unhandled {
+		p.unhandled = false
+		runtime.SetFinalizer(p, nil)
+	}
+	status, result, reason := p.status, p.result, p.reject
+	if status == PromisePending {
+		// Enqueue the promise for pending values
+		p.callbacks = append(p.callbacks, handle)
+	}
+	p.mutex.Unlock()
+
+	if status != PromisePending {
+		// Execute the promise with existing values
+		p.dispatch(func() { handle(status, result, reason) })
+	}
+}
+
+// ThenPromise chains to the promise returned by next, or rejects with its error.
+func (p *aPromise) ThenPromise(next func(Unknown) (Promise, error)) Promise {
+	return newPromise(p.derive(), func(resolve Resolver, reject Rejector) error {
+		p.Then(func(val Unknown) Unknown {
+			prom, err := next(val)
+			if err != nil {
+				return reject(err)
+			}
+			if isNilPromise(prom) {
+				return reject(ErrNilPromise) // a typed nil would panic on Then
+			}
+			prom.Then(resolve, reject)
+			return nil
+		}, reject)
 		return nil
 	})
 }
 
+// OnResolved is equivalent to Then(onsuccess, nil); rejections pass through unchanged.
+func (p *aPromise) OnResolved(onsuccess Resolver) Promise {
+	return p.Then(onsuccess, nil)
+}
+
 func (p *aPromise) Catch(catch Rejector) Promise {
 	return p.Then(nil, catch)
 }
 
+// ThenRetry is OnResolved for a flaky transform: when fn panics or returns an error, it is called again with
+// the same value, up to attempts calls in all, before the derived promise rejects with the last error. The
+// upstream promise is not re-created; rejections pass through without calling fn.
+func (p *aPromise) ThenRetry(attempts int, fn Resolver) Promise {
+	return p.OnResolved(func(u Unknown) Unknown {
+		var res Unknown
+		for i := 0; i < attempts || i == 0; i++ {
+			res = tryResolver(fn, u)
+			if err, ok := (res).(error); !ok || err == nil {
+				break
+			}
+		}
+		return res
+	})
+}
+
+// tryResolver calls fn, converting a panic into an error result.
+func tryResolver(fn Resolver, u Unknown) (res Unknown) {
+	defer func() {
+		if r := recover(); r != nil {
+			res = recoveredError(r)
+		}
+	}()
+	return fn(u)
+}
+
+// CatchOutcome is like Catch, but the handler receives the full outcome of the rejected promise.
+func (p *aPromise) CatchOutcome(catch func(*PromiseOutcome) Unknown) Promise {
+	return p.Catch(func(error) Unknown {
+		return catch(p.consumerOutcome())
+	})
+}
+
+// OrElse chains to the promise produced by fallback when p rejects; resolutions pass through.
+// A nil fallback promise rejects with ErrNilPromise.
+func (p *aPromise) OrElse(fallback func(error) Promise) Promise {
+	return p.Then(nil, func(err error) Unknown {
+		next := fallback(err)
+		if isNilPromise(next) {
+			return ErrNilPromise
+		}
+		return next
+	})
+}
+
+// OrValue resolves with defaultVal, even nil, when p rejects; resolutions pass through.
+func (p *aPromise) OrValue(defaultVal Unknown) Promise {
+	return p.OrValueFunc(func(error) Unknown {
+		return defaultVal
+	})
+}
+
+// OrValueFunc resolves with the value computed from the error when p rejects; resolutions pass through.
+// Like Catch, an error returned by fallback rejects instead.
+func (p *aPromise) OrValueFunc(fallback func(error) Unknown) Promise {
+	return newPromise(p.derive(), func(resolve Resolver, reject Rejector) error {
+		p.Then(resolve, func(err error) Unknown {
+			res := fallback(err)
+			if e, ok := (res).(error); ok && (e != nil) {
+				return reject(e)
+			}
+			return resolve(res)
+		})
+		return nil
+	})
+}
+
+// Recover handles a rejection of p with explicit semantics: an error returned by fn rejects the derived
+// promise, and otherwise the returned value, nil included, resolves it. A panic in fn rejects as well.
+// Resolutions pass through. Unlike Catch, nothing depends on what kind of value fn returns.
+func (p *aPromise) Recover(fn func(error) (Unknown, error)) Promise {
+	return newPromise(p.derive(), func(resolve Resolver, reject Rejector) error {
+		p.Then(resolve, func(err error) Unknown {
+			val, e := func() (val Unknown, e error) {
+				defer func() {
+					if r := recover(); r != nil {
+						e = recoveredError(r)
+					}
+				}()
+				return fn(err)
+			}()
+			if e != nil {
+				return reject(e)
+			}
+			return resolve(val)
+		})
+		return nil
+	})
+}
+
 func (p *aPromise) GetStatus() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	return PromiseStatusName[p.status]
 }
 
 func (p *aPromise) String() string {
-	return fmt.Sprintf("<Promise: %s> (%v, %v)", p.GetStatus(), p.result, p.reject)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return fmt.Sprintf("<Promise: %s> (%v, %v)", PromiseStatusName[p.status], p.result, p.reject)
 }
 
+// Channel delivers the outcome of p: once it settles, exactly one of the channels receives the result
+// or the error, and both are closed. The channels are buffered, so settling never blocks, even if
+// they are never read; each channel yields its value, if any, and then reports closed.
 func (p *aPromise) Channel() (<-chan Unknown, <-chan error) {
 	result := make(chan Unknown, 1)
 	errout := make(chan error, 1)
-	p.Then(func(i Unknown) Unknown {
-		result <- i
-		close(errout)
-		close(result)
-		return nil
-	}, func(e error) Unknown {
-		errout <- e
-		close(result)
-		close(errout)
-		return nil
+	p.subscribe(func(status uint, i Unknown, e error) {
+		if status == PromiseResolved {
+			result <- i
+			close(errout)
+			close(result)
+		} else {
+			errout <- e
+			close(result)
+			close(errout)
+		}
 	})
 	return result, errout
 }
 
+// WaitOutcome blocks until p settles, and returns its complete outcome.
+func (p *aPromise) WaitOutcome() *PromiseOutcome {
+	result, errout := p.Channel()
+	select {
+	case <-result:
+	case <-errout:
+	}
+	return p.consumerOutcome()
+}
+
+// Wait blocks until p settles, and returns its result or error.
 func (p *aPromise) Wait() (Unknown, error) {
+	return p.wait(context.Background())
+}
+
+// WaitContext is Wait that gives up once ctx is done, returning ctx.Err(). Giving up leaves p running;
+// for a CancelablePromise, WaitContext also cancels it.
+func (p *aPromise) WaitContext(ctx context.Context) (Unknown, error) {
+	return p.wait(ctx)
+}
+
+// Func adapts p to code that takes a func() (T, error): each call of the returned func blocks like Wait.
+func (p *aPromise) Func() func() (Unknown, error) {
+	return p.Wait
+}
+
+// FuncContext is Func, with each call giving up once ctx is done like WaitContext.
+func (p *aPromise) FuncContext(ctx context.Context) func() (Unknown, error) {
+	return func() (Unknown, error) {
+		return p.WaitContext(ctx)
+	}
+}
+
+// wait blocks until p settles or ctx is done.
+func (p *aPromise) wait(ctx context.Context) (Unknown, error) {
 	result, errout := p.Channel()
+	// Both channels are ready once p settles, but only one of them holds a value.
 	select {
-	case res := <-result:
+	case res, ok := <-result:
+		if !ok {
+			return nil, <-errout
+		}
 		return res, nil
-	case err := <-errout:
+	case err, ok := <-errout:
+		if !ok {
+			return <-result, nil
+		}
 		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// settle moves a pending promise into its final state and dispatches the queued callbacks.
+func (p *aPromise) settle(status uint, val Unknown, err error) bool {
+	p.mutex.Lock()
+	if p.status != PromisePending {
+		p.mutex.Unlock()
+		return false
+	}
+	p.status = status
+	p.result = val
+	p.reject = err
+	p.settled = now()
+	callbacks := p.callbacks
+	p.callbacks = nil
+	hooks := p.hooks
+	p.hooks = nil
+	if status == PromiseRejected && CaptureUnhandled && len(callbacks) == 0 {
+		trackUnhandled(p)
 	}
+	p.mutex.Unlock()
+
+	countSettle(status)
+	logSettle(p, status, err)
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	for _, han := range callbacks {
+		han := han
+		p.dispatch(func() { han(status, val, err) })
+	}
+	return true
 }
 
+// core returns the promise itself, and, promoted through embedding, the promise a wrapper such as a
+// CancelablePromise is built on.
+func (p *aPromise) core() *aPromise {
+	return p
+}
+
+// ErrSelfResolution is the rejection of a promise resolved with itself, which could otherwise never settle.
+var ErrSelfResolution = NewPromiseError("promise resolved with itself")
+
 func NewPromise(handler PromiseHandler) Promise {
-	prom := aPromise{
+	return newPromise(traits{}, handler)
+}
+
+// newPromise creates a promise with the given inherited traits.
+func newPromise(inherit traits, handler PromiseHandler) *aPromise {
+	prom := &aPromise{
 		status:    PromisePending,
 		reject:    nil,
 		result:    nil,
 		callbacks: make([]func(uint, Unknown, error), 0),
+		created:   now(),
+		traits:    inherit,
 	}
+	counters.created.Add(1)
 
 	var resolve Resolver
 	var reject Rejector
 
-	resolve = func(val Unknown) Unknown {
-		then, ok := (val).(Thenable)
-		if ok {
-
-			defer func() { // If the incoming promise errors, pass through rejection
-				r := recover()
-				err, _ := r.(error)
-				if err != nil {
-					reject(err)
+	// adopt follows then, reporting a value it delivers before returning, so resolve can loop rather than recurse.
+	adopt := func(then Thenable) (Unknown, bool) {
+		var mutex sync.Mutex // a Thenable may deliver on another goroutine, while adopt returns
+		var next Unknown
+		delivered, returned := false, false
+
+		func() {
+			defer func() { // If the incoming promise panics, reject
+				if r := recover(); r != nil {
+					reject(recoveredError(r))
 				}
 			}()
 
-			then.Then(resolve, reject)
-		} else if prom.status == PromisePending {
-			prom.status = PromiseResolved
-			prom.result = val
-			for _, han := range prom.callbacks {
-				go han(PromiseResolved, val, nil)
+			then.Then(func(val Unknown) Unknown {
+				mutex.Lock()
+				if !returned && !delivered {
+					next, delivered = val, true
+					mutex.Unlock()
+					return nil
+				}
+				mutex.Unlock()
+				return resolve(val) // delivered later, on a fresh stack
+			}, reject)
+		}()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		returned = true
+		return next, delivered
+	}
+
+	resolve = func(val Unknown) Unknown {
+		for {
+			then, ok := (val).(Thenable)
+			if !ok {
+				prom.settle(PromiseResolved, val, nil)
+				return nil
+			}
+			if inner, ok := then.(interface{ core() *aPromise }); ok && inner.core() == prom {
+				prom.settle(PromiseRejected, nil, ErrSelfResolution)
+				return nil
+			}
+			if val, ok = adopt(then); !ok {
+				return nil
 			}
-			// truncate
-			prom.callbacks = prom.callbacks[:0]
 		}
-		return nil
 	}
 
 	reject = func(err error) Unknown {
-		if prom.status == PromisePending {
-			prom.status = PromiseRejected
-			prom.reject = err
-			for _, han := range prom.callbacks {
-				go han(PromiseRejected, nil, err)
-			}
-			// truncate
-			prom.callbacks = prom.callbacks[:0]
-		}
+		prom.settle(PromiseRejected, nil, err)
 		return nil
 	}
 
-	e := handler(resolve, reject)
+	if prom.token != nil {
+		// onCancel may reject right away, or from another goroutine, so it must not run under the lock
+		stop := prom.token.onCancel(func() {
+			reject(ErrCanceled)
+		})
+		prom.mutex.Lock()
+		if prom.status != PromisePending {
+			stop()
+		} else {
+			prom.hooks = append(prom.hooks, stop)
+		}
+		prom.mutex.Unlock()
+	}
+
+	e := func() (e error) {
+		defer func() {
+			if r := recover(); r != nil {
+				e = recoveredError(r)
+			}
+		}()
+		return handler(resolve, reject)
+	}()
 
 	if e != nil {
+		if StrictMode && prom.GetStatus() != PromiseStatusName[PromisePending] {
+			reportDoubleSettle(prom, e)
+		}
 		reject(e)
 	}
 
-	return &prom
+	return prom
 }
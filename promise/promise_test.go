@@ -1,8 +1,11 @@
 package promise
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -29,16 +32,16 @@ func TestPromiseChain_withRecovery(t *testing.T) {
 			}
 		}()
 		return nil
-	}).Then(func(i Unknown) Unknown {
+	}).OnResolved(func(i Unknown) Unknown {
 		return (i).(int) * 2
-	}, nil).Catch(func(e error) Unknown {
+	}).Catch(func(e error) Unknown {
 		t.Logf("Caught error: %s", e)
 		t.Log("Recovering with value (5) for next hop")
 		did_reset = true
 		return 5 // overrides with alternate resolution
-	}).Then(func(i Unknown) Unknown {
+	}).OnResolved(func(i Unknown) Unknown {
 		return 13 * (i).(int)
-	}, nil)
+	})
 
 	// Wait() blocks on internal Channel() channels, so no need for time.After() here
 	res, err := result.Wait()
@@ -62,279 +65,962 @@ func TestPromiseChain_withRecovery(t *testing.T) {
 func TestPromise_Race(t *testing.T) {
 
 	start := []Promise{
+		RejectAfter(50*time.Millisecond, errors.New("FAIL")),
+		ResolveAfter(200*time.Millisecond, "One"),
+		ResolveAfter(100*time.Millisecond, "Two"),
+	}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(50 * time.Millisecond)
-				reject(errors.New("FAIL"))
-			}()
+	// the first promise to settle is the rejection
+	res, err := Race(start...).Wait()
+	Assert(t, err != nil && err.Error() == "FAIL", "Race produced unexpected error: %v", err)
+	Assert(t, res == nil, "Race produced unexpected result: %v", res)
 
-			return nil
-		}),
+	// without the rejection, the fastest resolution wins
+	res, err = Race(start[1:]...).Wait()
+	Assert(t, err == nil && res == "Two", "Race produced unexpected result: %v, %v", res, err)
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(200 * time.Millisecond)
-				resolve("One")
-			}()
+}
 
-			return nil
-		}),
+func TestPromise_RaceIndex(t *testing.T) {
+	res, err := RaceIndex(
+		ResolveAfter(200*time.Millisecond, "One"),
+		ResolveAfter(10*time.Millisecond, "Two"),
+		ResolveAfter(100*time.Millisecond, "Three"),
+	).Wait()
+	winner, ok := (res).(IndexedValue)
+	Assert(t, err == nil && ok, "RaceIndex() did not resolve with an IndexedValue: %v, %v", res, err)
+	Assert(t, winner.Index == 1 && winner.Value == "Two", "RaceIndex() reported the wrong winner: %v", winner)
+
+	fail := errors.New("FAIL")
+	_, err = RaceIndex(ResolveAfter(100*time.Millisecond, "One"), RejectAfter(10*time.Millisecond, fail)).Wait()
+	indexed, ok := (err).(*IndexedError)
+	Assert(t, ok && indexed.Index == 1 && indexed.Err == fail, "RaceIndex() reported the wrong rejection: %v", err)
+}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(100 * time.Millisecond)
-				resolve("Two")
-			}()
+func TestPromise_All(t *testing.T) {
+	// one of these rejects, to verify correct failure behavior
+	willfail := []Promise{
+		RejectAfter(50*time.Millisecond, errors.New("FAIL")),
+		ResolveAfter(200*time.Millisecond, "One"),
+		ResolveAfter(100*time.Millisecond, "Two"),
+	}
 
-			return nil
-		}),
+	// This case will fail
+	res, err := All(willfail...).Wait()
+	Assert(t, err != nil && err.Error() == "FAIL", "All() failed with an unexpected error: %v", err)
+	Assert(t, res == nil, "All() passed when it should have failed [%v]", res)
+
+	willpass := []Promise{
+		ResolveAfter(50*time.Millisecond, "Zero"),
+		ResolveAfter(200*time.Millisecond, "One"),
+		ResolveAfter(100*time.Millisecond, "Two"),
 	}
 
-	Race(start...).Then(func(u Unknown) Unknown {
-		Assert(t, u == "Two", "Race produced unexpected result: %v", u)
-		return nil
-	}, nil)
+	// This case will pass
+	res, err = All(willpass...).Wait()
+	Assert(t, err == nil, "All() failed with an unexpected error: %s", err)
 
+	for i, val := range []string{"Zero", "One", "Two"} {
+		value, err := AtString(res, i)
+		Assert(t, err == nil, "Could not read the combined result of all promises: %s", err)
+		Assert(t, value == val, "All() produced a mismatching value: %v != %v", value, val)
+	}
 }
 
-func TestPromise_All(t *testing.T) {
-	// one of these rejects, to verify correct failure behavior
+func TestPromise_Any(t *testing.T) {
+	willpass := []Promise{
+		RejectAfter(50*time.Millisecond, fmt.Errorf("error: %d", 0)),
+		ResolveAfter(200*time.Millisecond, "One"),
+		ResolveAfter(100*time.Millisecond, "Two"),
+	}
+
+	// This case will pass
+	res, err := Any(willpass...).Wait()
+	Assert(t, err == nil, "Any() failed with an unexpected error: %s", err)
+
+	value, ok := (res).(string)
+
+	if !ok {
+		t.Fatalf("Could not coerce the result of a promise")
+	}
+
+	Assert(t, value == "Two", "Any() produced a mismatching value: %v != %v", value, "Two")
+
 	willfail := []Promise{
+		RejectAfter(50*time.Millisecond, fmt.Errorf("error: %d", 1)),
+		RejectAfter(200*time.Millisecond, fmt.Errorf("error: %d", 2)),
+		RejectAfter(100*time.Millisecond, fmt.Errorf("error: %d", 3)),
+	}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(50 * time.Millisecond)
-				reject(errors.New("FAIL"))
-			}()
+	// This case will fail, with the (chronologically) last error above
+	res, err = Any(willfail...).Wait()
+	Assert(t, res == nil, "Any() passed when it should have failed [%v]", res)
 
-			return nil
-		}),
+	t.Logf("(expected) failure: %s", err)
+	multi, ok := (err).(*MultiPromiseError)
+
+	if !ok || (multi == nil) {
+		t.Fatalf("Any() failed with an unexpected error: %v", err)
+	}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(200 * time.Millisecond)
-				resolve("One")
-			}()
+	for i, o := range multi.Outcomes() {
+		t.Logf("(expected) error: %v", o)
+		Assert(t, o.Reason.Error() == fmt.Sprintf("error: %d", i+1),
+			"Error did not match the expected format: %s", o.Reason)
+	}
 
-			return nil
-		}),
+}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(100 * time.Millisecond)
-				resolve("Two")
-			}()
+func TestPromise_AllSettled(t *testing.T) {
 
-			return nil
-		}),
+	promises := []Promise{
+		ResolveAfter(300*time.Millisecond, 1),
+		ResolveAfter(200*time.Millisecond, 2),
+		RejectAfter(100*time.Millisecond, errors.New("FOILED!")),
 	}
 
-	// This case will fail
-	All(willfail...).Then(func(u Unknown) Unknown {
-		Assert(t, false, "All() passed when it should have failed [%u]", u)
+	res, err := AllSettled(promises...).Wait()
+	Assert(t, err == nil, "AllSettled() failed with an unexpected error: %s", err)
+
+	result, ok := (res).([]*PromiseOutcome)
+
+	if !(ok && (result != nil)) {
+		t.Fatalf("Could not coerce promise outcomes for AllSettled()")
+	}
+
+	Assert(t, len(promises) == len(result), "Results did not match promise input for AllSettled()")
+
+	Assert(t, result[0].Reason == nil, "First promise yielded unexpected error: %s", result[0].Reason)
+	Assert(t, result[1].Reason == nil, "Second promise yielded unexpected error: %s", result[1].Reason)
+	Assert(t, result[2].Reason != nil, "Third promise should have failed, but did not: %v", result[2].Result)
+
+	Assert(t, result[0].Result == 1, "First result did not match expected value: %v", result[0].Result)
+	Assert(t, result[1].Result == 2, "Second result did not match expected value: %v", result[1].Result)
+
+}
+
+func TestPromise_ThenPromise(t *testing.T) {
+	chained := Resolve(2).ThenPromise(func(u Unknown) (Promise, error) {
+		return Resolve((u).(int) * 3), nil
+	})
+
+	res, err := chained.Wait()
+	Assert(t, err == nil, "ThenPromise yielded an unexpected error: %s", err)
+	Assert(t, res == 6, "ThenPromise produced an unexpected result: %v", res)
+
+	fail := errors.New("no next step")
+	failed := Resolve(2).ThenPromise(func(u Unknown) (Promise, error) {
+		return nil, fail
+	})
+
+	res, err = failed.Wait()
+	Assert(t, err == fail, "ThenPromise did not reject with the handler error: %v", err)
+	Assert(t, failed.GetStatus() == "Rejected", "ThenPromise status was not Rejected, found %v", failed.GetStatus())
+
+	inner := Resolve(2).ThenPromise(func(u Unknown) (Promise, error) {
+		return Reject(fail), nil
+	})
+
+	_, err = inner.Wait()
+	Assert(t, err == fail, "ThenPromise did not adopt the chained rejection: %v", err)
+
+	_, err = Resolve(2).ThenPromise(func(u Unknown) (Promise, error) {
+		return nil, nil
+	}).Wait()
+	Assert(t, err == ErrNilPromise, "ThenPromise did not reject a nil promise: %v", err)
+
+	_, err = Resolve(2).ThenPromise(func(u Unknown) (Promise, error) {
+		var p *aPromise
+		return p, nil
+	}).Wait()
+	Assert(t, err == ErrNilPromise, "ThenPromise did not reject a typed-nil promise: %v", err)
+}
+
+func TestPromise_FilterFulfilled(t *testing.T) {
+	res, err := FilterFulfilled(
+		Resolve("Zero"),
+		Reject(errors.New("FAIL")),
+		Resolve("Two"),
+	).Wait()
+
+	Assert(t, err == nil, "FilterFulfilled() failed with an unexpected error: %s", err)
+
+	values, ok := (res).([]Unknown)
+	Assert(t, ok, "Could not coerce the filtered results: %v", res)
+	Assert(t, len(values) == 2, "FilterFulfilled() kept %d values, expected 2", len(values))
+	Assert(t, values[0] == "Zero" && values[1] == "Two", "FilterFulfilled() produced mismatching values: %v", values)
+
+	res, err = FilterFulfilled(Reject(errors.New("FAIL"))).Wait()
+	values, _ = (res).([]Unknown)
+	Assert(t, err == nil && len(values) == 0, "FilterFulfilled() did not resolve empty when all failed: %v, %v", res, err)
+}
+
+func TestPromise_OrElse(t *testing.T) {
+	primary := RejectAfter(50*time.Millisecond, errors.New("primary unavailable"))
+
+	var cause error
+	res, err := primary.OrElse(func(e error) Promise {
+		cause = e
+		return ResolveAfter(50*time.Millisecond, "backup")
+	}).Wait()
+
+	Assert(t, err == nil, "OrElse() failed with an unexpected error: %s", err)
+	Assert(t, res == "backup", "OrElse() produced an unexpected result: %v", res)
+	Assert(t, cause != nil && cause.Error() == "primary unavailable", "OrElse() fallback received an unexpected error: %v", cause)
+
+	res, err = Resolve("primary").OrElse(func(e error) Promise {
+		t.Errorf("OrElse() invoked the fallback for a resolved promise")
 		return nil
-	}, func(e error) Unknown {
-		Assert(t, e.Error() == "FAIL", "All() failed with an unexpected error: %s", e)
+	}).Wait()
+	Assert(t, err == nil && res == "primary", "OrElse() did not pass the resolution through: %v, %v", res, err)
+
+	_, err = Reject(errors.New("failed")).OrElse(func(error) Promise {
 		return nil
-	})
+	}).Wait()
+	Assert(t, err == ErrNilPromise, "OrElse() did not reject a nil fallback: %v", err)
+
+	_, err = Reject(errors.New("failed")).OrElse(func(error) Promise {
+		var p *aPromise
+		return p
+	}).Wait()
+	Assert(t, err == ErrNilPromise, "OrElse() did not reject a typed-nil fallback: %v", err)
+}
 
-	willpass := []Promise{
+func TestPromise_AllKeyedSettled(t *testing.T) {
+	res, err := AllKeyedSettled(map[string]Promise{
+		"database": Resolve("postgres://"),
+		"cache":    Reject(errors.New("cache section missing")),
+		"logging":  Resolve("debug"),
+	}).Wait()
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(50 * time.Millisecond)
-				resolve("Zero")
-			}()
+	Assert(t, err == nil, "AllKeyedSettled() failed with an unexpected error: %s", err)
 
-			return nil
-		}),
+	result, ok := (res).(map[string]*PromiseOutcome)
+	if !ok {
+		t.Fatalf("Could not coerce keyed outcomes for AllKeyedSettled()")
+	}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(200 * time.Millisecond)
-				resolve("One")
-			}()
+	Assert(t, len(result) == 3, "AllKeyedSettled() produced %d outcomes, expected 3", len(result))
+	Assert(t, result["database"].Result == "postgres://", "Unexpected database outcome: %v", result["database"])
+	Assert(t, result["logging"].Result == "debug", "Unexpected logging outcome: %v", result["logging"])
+	Assert(t, result["cache"].Reason != nil, "Cache section should have failed: %v", result["cache"])
 
-			return nil
-		}),
+	res, _ = AllKeyedSettled(map[string]Promise{}).Wait()
+	result, _ = (res).(map[string]*PromiseOutcome)
+	Assert(t, result != nil && len(result) == 0, "AllKeyedSettled() of an empty map did not resolve empty: %v", res)
+}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(100 * time.Millisecond)
-				resolve("Two")
-			}()
+func TestPromise_AllSettled_inputOrder(t *testing.T) {
 
-			return nil
-		}),
+	promises := []Promise{
+		ResolveAfter(150*time.Millisecond, "first in, last out"),
+		RejectAfter(100*time.Millisecond, errors.New("middle")),
+		ResolveAfter(50*time.Millisecond, "last in, first out"),
 	}
 
-	// This case will pass
-	All(willpass...).Then(func(u Unknown) Unknown {
-		values, ok := (u).([]string)
+	res, err := AllSettled(promises...).Wait()
+	Assert(t, err == nil, "AllSettled() failed with an unexpected error: %s", err)
+
+	result, ok := (res).([]*PromiseOutcome)
+	if !ok || len(result) != len(promises) {
+		t.Fatalf("Could not coerce promise outcomes for AllSettled(): %v", res)
+	}
+
+	Assert(t, result[0].Result == "first in, last out", "First outcome does not belong to the first promise: %v", result[0])
+	Assert(t, result[1].Reason != nil && result[1].Reason.Error() == "middle", "Second outcome does not belong to the second promise: %v", result[1])
+	Assert(t, result[2].Result == "last in, first out", "Third outcome does not belong to the third promise: %v", result[2])
+}
+
+func TestPromise_CatchOutcome(t *testing.T) {
+	fail := errors.New("FOILED!")
+
+	var seen *PromiseOutcome
+	res, err := Reject(fail).CatchOutcome(func(o *PromiseOutcome) Unknown {
+		seen = o
+		return "recovered"
+	}).Wait()
+
+	Assert(t, err == nil && res == "recovered", "CatchOutcome() did not recover: %v, %v", res, err)
+	Assert(t, seen != nil && seen.Status == "Rejected", "CatchOutcome() received an unexpected outcome: %v", seen)
+	Assert(t, seen.Reason == fail, "CatchOutcome() outcome has an unexpected reason: %v", seen.Reason)
+
+	rethrown := errors.New("rethrown")
+	_, err = Reject(fail).CatchOutcome(func(o *PromiseOutcome) Unknown {
+		return rethrown
+	}).Wait()
+	Assert(t, err == rethrown, "CatchOutcome() did not re-reject with the returned error: %v", err)
+}
+
+func TestPromise_Resolve_shared(t *testing.T) {
+	Assert(t, Resolve(nil) == ResolvedNil(), "Resolve(nil) did not return the shared promise")
+	Assert(t, Resolve(true) == Resolve(true), "Resolve(true) did not return a shared promise")
+	Assert(t, Resolve(1) != Resolve(1), "Resolve(1) unexpectedly returned a shared promise")
+
+	res, err := Resolve(false).OnResolved(func(u Unknown) Unknown {
+		return !(u).(bool)
+	}).Wait()
+	Assert(t, err == nil && res == true, "Shared promise produced an unexpected derivative: %v, %v", res, err)
+
+	res, err = Resolve(false).Wait()
+	Assert(t, err == nil && res == false, "Shared promise was changed by a consumer: %v, %v", res, err)
+
+	res, err = Resolve([]int{1}).Wait()
+	Assert(t, err == nil && len((res).([]int)) == 1, "Resolve() of an unhashable value failed: %v, %v", res, err)
+
+	shared := Resolve(true).Outcome()
+	Assert(t, shared.CreatedAt.IsZero() && shared.SettledAt.IsZero(), "Shared promise has timestamps: %v, %v", shared.CreatedAt, shared.SettledAt)
+
+	start := time.Now()
+	stats := Stats([]*PromiseOutcome{shared, {Status: "Resolved", CreatedAt: start, SettledAt: start.Add(time.Second)}})
+	Assert(t, stats.MinLatency == time.Second && stats.MaxLatency == time.Second, "Stats() counted a shared promise's latency: %+v", stats)
+}
+
+func TestPromise_ResolveVoid(t *testing.T) {
+	res, err := ResolveVoid().Wait()
+	Assert(t, err == nil && res == Void, "ResolveVoid() did not resolve with Void: %v, %v", res, err)
+	Assert(t, Resolve(Void) == ResolveVoid(), "Resolve(Void) did not return the shared promise")
+
+	res, _ = Resolve(nil).Wait()
+	Assert(t, res != Void, "A nil result compared equal to Void")
+
+	res, _ = Resolve(1).OnResolved(func(Unknown) Unknown {
+		return Void
+	}).Wait()
+	Assert(t, res == Void, "Void did not pass through a chain: %v", res)
+}
+
+func BenchmarkResolve_shared(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Resolve(nil)
+	}
+}
+
+func BenchmarkResolve_allocated(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Resolve(i + 1)
+	}
+}
+
+func TestPromise_All_concurrent(t *testing.T) {
+	proms := make([]Promise, 1000)
+	for i := range proms {
+		proms[i] = Resolve(i)
+	}
+
+	res, err := All(proms...).Wait()
+	values, _ := (res).([]Unknown)
+	Assert(t, err == nil && len(values) == len(proms), "All() did not resolve with every result: %v", err)
+	for i, v := range values {
+		Assert(t, v == i, "All() result %d was %v", i, v)
+	}
+}
 
-		if !ok {
-			t.Fatalf("Could not coerce the combined result of all promises")
+func TestPromise_Any_concurrentRejections(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		start := make(chan struct{})
+		proms := make([]Promise, 100)
+		for i := range proms {
+			i := i
+			proms[i] = NewPromise(func(resolve Resolver, reject Rejector) error {
+				go func() {
+					<-start
+					reject(fmt.Errorf("error: %d", i))
+				}()
+				return nil
+			})
 		}
 
-		for i, val := range []string{"Zero", "One", "Two"} {
-			Assert(t, values[i] == val, "All() produced a mismatching value: %v != %v", values[i], val)
+		var rejections int32
+		prom := Any(proms...)
+		caught := prom.Catch(func(error) Unknown {
+			atomic.AddInt32(&rejections, 1)
+			return true // a nil recovery would leave caught pending
+		})
+		close(start)
+
+		_, err := prom.Wait()
+		multi, ok := (err).(*MultiPromiseError)
+		Assert(t, ok && len(multi.Errors()) == len(proms), "Any() did not reject with every failure: %v", err)
+
+		caught.Wait()
+		count := atomic.LoadInt32(&rejections)
+		Assert(t, count == 1, "Any() rejected %d times", count)
+	}
+}
+
+func TestPromise_AllSettled_concurrent(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		start := make(chan struct{})
+		proms := make([]Promise, 100)
+		for i := range proms {
+			i := i
+			proms[i] = NewPromise(func(resolve Resolver, reject Rejector) error {
+				go func() {
+					<-start
+					if i%2 == 0 {
+						resolve(i)
+					} else {
+						reject(fmt.Errorf("error: %d", i))
+					}
+				}()
+				return nil
+			})
 		}
 
-		return nil
-	}, func(e error) Unknown {
-		Assert(t, true, "All() failed with an unexpected error: %s", e)
-		return nil
-	})
+		prom := AllSettled(proms...)
+		close(start)
+
+		res, err := prom.Wait()
+		outcomes, _ := (res).([]*PromiseOutcome)
+		Assert(t, err == nil && len(outcomes) == len(proms), "AllSettled() did not resolve with every outcome: %v", err)
+		for i, o := range outcomes {
+			Assert(t, o.Status != "Pending", "AllSettled() resolved before input %d settled", i)
+		}
+	}
 }
 
-func TestPromise_Any(t *testing.T) {
-	willpass := []Promise{
+func BenchmarkAll_1000(b *testing.B) {
+	b.ReportAllocs()
+	proms := make([]Promise, 1000)
+	for i := range proms {
+		proms[i] = Resolve(i)
+	}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(50 * time.Millisecond)
-				reject(fmt.Errorf("error: %d", 0))
-			}()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		All(proms...).Wait()
+	}
+}
 
+func BenchmarkThen_bothHandlers(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Resolve(i).Then(func(u Unknown) Unknown {
+			return u
+		}, func(e error) Unknown {
 			return nil
-		}),
-
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(200 * time.Millisecond)
-				resolve("One")
-			}()
+		}).Wait()
+	}
+}
 
+func BenchmarkThen_thenCatch(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Resolve(i).Then(func(u Unknown) Unknown {
+			return u
+		}, nil).Catch(func(e error) Unknown {
 			return nil
-		}),
+		}).Wait()
+	}
+}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(100 * time.Millisecond)
-				resolve("Two")
-			}()
+func TestPromise_WaitOutcome(t *testing.T) {
+	outcome := ResolveAfter(10*time.Millisecond, "done").WaitOutcome()
+	Assert(t, outcome.Status == "Resolved" && outcome.Result == "done", "WaitOutcome() returned an unexpected outcome: %v", outcome)
+	Assert(t, !outcome.SettledAt.Before(outcome.CreatedAt.Add(10*time.Millisecond)), "WaitOutcome() returned before settling: %v", outcome)
 
-			return nil
-		}),
+	fail := errors.New("FAIL")
+	outcome = RejectAfter(10*time.Millisecond, fail).WaitOutcome()
+	Assert(t, outcome.Status == "Rejected" && outcome.Reason == fail, "WaitOutcome() returned an unexpected outcome: %v", outcome)
+}
+
+func TestPromise_nilInputs(t *testing.T) {
+	for name, combinator := range map[string]func(...Promise) Promise{
+		"Race":       Race,
+		"RaceIndex":  RaceIndex,
+		"All":        All,
+		"Any":        Any,
+		"AllSettled": AllSettled,
+	} {
+		_, err := combinator(Resolve(1), nil).Wait()
+		indexed, ok := (err).(*IndexedError)
+		Assert(t, ok && indexed.Index == 1 && errors.Is(err, ErrNilPromise), "%s() did not reject a nil input: %v", name, err)
 	}
+}
 
-	// This case will pass
-	Any(willpass...).Then(func(u Unknown) Unknown {
-		value, ok := (u).(string)
+func TestPromise_WithValue(t *testing.T) {
+	type traceKey struct{}
 
-		if !ok {
-			t.Fatalf("Could not coerce the result of a promise")
+	root := Resolve(1)
+	traced := root.WithValue(traceKey{}, "trace-1")
+	chain := traced.OnResolved(func(u Unknown) Unknown {
+		return (u).(int) + 1
+	}).Catch(func(error) Unknown { return nil })
+
+	Assert(t, chain.Value(traceKey{}) == "trace-1", "Value() was not inherited along the chain: %v", chain.Value(traceKey{}))
+	Assert(t, root.Value(traceKey{}) == nil, "WithValue() changed the original promise")
+
+	res, err := chain.Wait()
+	Assert(t, err == nil && res == 2, "WithValue() changed the outcome: %v, %v", res, err)
+
+	shadowed := chain.WithValue(traceKey{}, "trace-2").WithValue("user", 7)
+	Assert(t, shadowed.Value(traceKey{}) == "trace-2" && shadowed.Value("user") == 7, "WithValue() did not shadow earlier values")
+	Assert(t, chain.Value(traceKey{}) == "trace-1" && chain.Value("user") == nil, "WithValue() leaked into an earlier promise")
+}
+
+func TestPromise_Then_typedNilPromise(t *testing.T) {
+	_, err := Resolve(1).Then(func(Unknown) Unknown {
+		var p *aPromise
+		return Promise(p)
+	}, nil).Wait()
+	Assert(t, err == ErrNilPromise, "Then() did not reject a typed-nil promise: %v", err)
+
+	_, err = Reject(errors.New("failed")).Then(nil, func(error) Unknown {
+		var p *aPromise
+		return Promise(p)
+	}).Wait()
+	Assert(t, err == ErrNilPromise, "Then() did not reject a typed-nil promise from onfail: %v", err)
+
+	var p *cancelablePromise
+	_, err = All(Resolve(1), p).Wait()
+	Assert(t, errors.Is(err, ErrNilPromise), "All() did not reject a typed-nil promise: %v", err)
+}
+
+func TestPromise_ThenRetry(t *testing.T) {
+	calls := 0
+	upstream := 0
+	source := Do(func() (Unknown, error) {
+		upstream += 1
+		return "42", nil
+	})
+
+	res, err := source.ThenRetry(3, func(u Unknown) Unknown {
+		calls += 1
+		switch calls {
+		case 1:
+			return errors.New("resource busy")
+		case 2:
+			panic("resource raced")
 		}
+		return (u).(string) + "!"
+	}).Wait()
+	Assert(t, err == nil && res == "42!", "ThenRetry() did not succeed on the third call: %v, %v", res, err)
+	Assert(t, calls == 3 && upstream == 1, "ThenRetry() made %d calls and %d upstream calls", calls, upstream)
+
+	fail := errors.New("FAIL")
+	calls = 0
+	_, err = source.ThenRetry(2, func(Unknown) Unknown {
+		calls += 1
+		return fail
+	}).Wait()
+	Assert(t, err == fail && calls == 2, "ThenRetry() did not give up after 2 calls: %v after %d", err, calls)
+}
 
-		Assert(t, value == "Two", "Any() produced a mismatching value: %v != %v", value, "Two")
+func TestPromise_Recover(t *testing.T) {
+	fail := errors.New("FAIL")
+
+	res, err := Reject(fail).Recover(func(error) (Unknown, error) {
+		return nil, nil
+	}).Wait()
+	Assert(t, err == nil && res == nil, "Recover() did not recover with nil: %v, %v", res, err)
+
+	res, err = Reject(fail).Recover(func(error) (Unknown, error) {
+		return errors.New("an error value"), nil
+	}).Wait()
+	Assert(t, err == nil && res != nil && res.(error).Error() == "an error value", "Recover() did not resolve with an error value: %v, %v", res, err)
+
+	wrapped := fmt.Errorf("rethrown: %w", fail)
+	_, err = Reject(fail).Recover(func(error) (Unknown, error) {
+		return "ignored", wrapped
+	}).Wait()
+	Assert(t, err == wrapped, "Recover() did not re-reject: %v", err)
+
+	_, err = Reject(fail).Recover(func(error) (Unknown, error) {
+		panic("recovery failed")
+	}).Wait()
+	Assert(t, err != nil, "Recover() did not reject on panic")
+
+	res, err = Resolve(7).Recover(func(error) (Unknown, error) {
+		return 0, nil
+	}).Wait()
+	Assert(t, err == nil && res == 7, "Recover() changed a resolution: %v, %v", res, err)
+}
 
-		return nil
-	}, func(e error) Unknown {
-		Assert(t, true, "Any() failed with an unexpected error: %s", e)
-		return nil
+type hopError struct {
+	hops int
+}
+
+func (e *hopError) Error() string {
+	return fmt.Sprintf("failed after %d hops", e.hops)
+}
+
+func TestPromise_Then_passesErrorsThrough(t *testing.T) {
+	original := &hopError{hops: 5}
+	chain := Reject(original)
+	for i := 0; i < 5; i++ {
+		chain = chain.Then(func(u Unknown) Unknown {
+			return u
+		}, nil)
+	}
+	chain = chain.OnResolved(func(u Unknown) Unknown { return u }).OrValueFunc(func(err error) Unknown {
+		return err // returning the error rejects with it, untouched
 	})
 
-	willfail := []Promise{
+	_, err := chain.Wait()
+	Assert(t, err == original, "The chain did not propagate the exact error value: %#v", err)
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(50 * time.Millisecond)
-				reject(fmt.Errorf("error: %d", 1))
-			}()
+	var typed *hopError
+	Assert(t, errors.As(err, &typed) && typed.hops == 5, "errors.As did not find the typed error: %v", err)
+}
 
-			return nil
-		}),
+func TestPromise_OnResolved(t *testing.T) {
+	res, err := Resolve(2).OnResolved(func(u Unknown) Unknown {
+		return (u).(int) + 1
+	}).Wait()
+	Assert(t, err == nil && res == 3, "OnResolved() produced an unexpected outcome: %v, %v", res, err)
+
+	fail := errors.New("FAIL")
+	_, err = Reject(fail).OnResolved(func(u Unknown) Unknown {
+		t.Errorf("OnResolved() invoked the resolver for a rejected promise")
+		return u
+	}).Wait()
+	Assert(t, err == fail, "OnResolved() did not pass the rejection through: %v", err)
+}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(200 * time.Millisecond)
-				reject(fmt.Errorf("error: %d", 2))
-			}()
+func TestPromise_OrValue(t *testing.T) {
+	fail := errors.New("FAIL")
 
-			return nil
-		}),
+	res, err := Reject(fail).OrValue("default").Wait()
+	Assert(t, err == nil && res == "default", "OrValue() did not recover with the default: %v, %v", res, err)
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(100 * time.Millisecond)
-				reject(fmt.Errorf("error: %d", 3))
-			}()
+	recovered := Reject(fail).OrValue(nil)
+	res, err = recovered.Wait()
+	Assert(t, err == nil && res == nil, "OrValue(nil) did not recover: %v, %v", res, err)
+	Assert(t, recovered.GetStatus() == "Resolved", "OrValue(nil) status was not Resolved, found %v", recovered.GetStatus())
 
-			return nil
-		}),
+	res, err = Resolve("value").OrValue("default").Wait()
+	Assert(t, err == nil && res == "value", "OrValue() did not pass the resolution through: %v, %v", res, err)
+}
+
+func TestPromise_OrValueFunc(t *testing.T) {
+	fail := errors.New("FAIL")
+
+	res, err := Reject(fail).OrValueFunc(func(e error) Unknown {
+		return "recovered from " + e.Error()
+	}).Wait()
+	Assert(t, err == nil && res == "recovered from FAIL", "OrValueFunc() did not recover: %v, %v", res, err)
+
+	res, err = Resolve("value").OrValueFunc(func(e error) Unknown {
+		t.Errorf("OrValueFunc() invoked the fallback for a resolved promise")
+		return nil
+	}).Wait()
+	Assert(t, err == nil && res == "value", "OrValueFunc() did not pass the resolution through: %v, %v", res, err)
+
+	rethrown := errors.New("rethrown")
+	_, err = Reject(fail).OrValueFunc(func(e error) Unknown {
+		return rethrown
+	}).Wait()
+	Assert(t, err == rethrown, "OrValueFunc() did not reject with the returned error: %v", err)
+}
+
+func TestPromise_Channel(t *testing.T) {
+	fail := errors.New("FAIL")
+
+	result, errout := Resolve("value").Channel()
+	<-time.After(10 * time.Millisecond) // let the promise deliver before reading
+	res, ok := <-result
+	Assert(t, ok && res == "value", "Channel() did not deliver the result: %v, %v", res, ok)
+	_, ok = <-result
+	Assert(t, !ok, "Channel() result was not closed after delivery")
+	err, ok := <-errout
+	Assert(t, !ok && err == nil, "Channel() error channel was not closed empty: %v", err)
+
+	result, errout = Reject(fail).Channel()
+	<-time.After(10 * time.Millisecond)
+	err, ok = <-errout
+	Assert(t, ok && err == fail, "Channel() did not deliver the error: %v, %v", err, ok)
+	_, ok = <-errout
+	Assert(t, !ok, "Channel() error channel was not closed after delivery")
+	res, ok = <-result
+	Assert(t, !ok && res == nil, "Channel() result was not closed empty: %v", res)
+
+	// unread channels must not hold anything up
+	for i := 0; i < 100; i++ {
+		Reject(fail).Channel()
 	}
 
-	// This case will fail, with the (chronologically) last error above
-	Any(willfail...).Then(func(u Unknown) Unknown {
-		Assert(t, false, "Any() passed when it should have failed [%u]", u)
+	// Wait must pick the channel holding the outcome even when both are ready,
+	// as they are when an inline executor delivers before Wait selects
+	SetExecutor(InlineExecutor)
+	defer SetExecutor(nil)
+	for i := 0; i < 1000; i++ {
+		res, err := Resolve(i + 1).Wait()
+		Assert(t, err == nil && res == i+1, "Wait() lost a resolution: %v, %v", res, err)
+		_, err = Reject(fail).Wait()
+		Assert(t, err == fail, "Wait() lost a rejection: %v", err)
+	}
+}
+
+func TestPromise_PendingCallbacks(t *testing.T) {
+	var resolve Resolver
+	prom := NewPromise(func(r Resolver, _ Rejector) error {
+		resolve = r
 		return nil
-	}, func(e error) Unknown {
-		t.Logf("(expected) failure: %s", e)
-		multi, ok := (e).(*MultiPromiseError)
-
-		if ok && (multi != nil) {
-			for i, o := range multi.Outcomes() {
-				t.Logf("(expected) error: %v", o)
-				Assert(t, o.Reason.Error() == fmt.Sprintf("error: %d", i+1),
-					"Error did not match the expected format: %s", o.Reason)
-			}
-		}
+	})
+	Assert(t, prom.PendingCallbacks() == 0, "New promise has callbacks: %d", prom.PendingCallbacks())
+
+	prom.Then(nil, nil)
+	prom.Catch(func(error) Unknown { return nil })
+	Assert(t, prom.PendingCallbacks() == 2, "Expected 2 pending callbacks, found %d", prom.PendingCallbacks())
+
+	resolve("done")
+	Assert(t, prom.PendingCallbacks() == 0, "Settled promise kept its callbacks: %d", prom.PendingCallbacks())
+}
 
+func TestPromise_MultiPromiseError(t *testing.T) {
+	one, two := errors.New("error one"), errors.New("error two")
+	err := NewMultiPromiseError("all promises failed", []Promise{Resolve("ok"), Reject(one), Reject(two)})
+
+	expected := "all promises failed: 2 of 3 failed [1: error one; 2: error two]"
+	Assert(t, err.Error() == expected, "MultiPromiseError message was %q, expected %q", err.Error(), expected)
+
+	errs := err.Errors()
+	Assert(t, len(errs) == 2 && errs[0] == one && errs[1] == two, "Errors() returned unexpected reasons: %v", errs)
+	Assert(t, errors.Is(err, two), "MultiPromiseError does not unwrap to its reasons")
+}
+
+func TestMultiPromiseError_GroupByMessage(t *testing.T) {
+	proms := []Promise{Resolve("ok"), Reject(errors.New("timeout"))}
+	for i := 0; i < 15; i++ {
+		proms = append(proms, Reject(fmt.Errorf("connection refused")))
+	}
+	proms = append(proms, Reject(errors.New("timeout")), Reject(errors.New("no route")))
+	err := NewMultiPromiseError("all promises failed", proms)
+
+	groups := err.GroupByMessage()
+	Assert(t, len(groups) == 3 && groups["connection refused"] == 15 && groups["timeout"] == 2 && groups["no route"] == 1,
+		"GroupByMessage() produced unexpected groups: %v", groups)
+
+	expected := "all promises failed: 18 of 19 failed [connection refused (×15); timeout (×2); no route]"
+	Assert(t, err.Error() == expected, "MultiPromiseError message was %q, expected %q", err.Error(), expected)
+
+	// a small batch keeps the indices, even with a repeat
+	repeated := errors.New("x")
+	small := NewMultiPromiseError("all promises failed", []Promise{Reject(repeated), Reject(repeated)})
+	expected = "all promises failed: 2 of 2 failed [0: x; 1: x]"
+	Assert(t, small.Error() == expected, "MultiPromiseError message was %q, expected %q", small.Error(), expected)
+}
+
+func TestPromise_AllWaitFail(t *testing.T) {
+	fail := errors.New("FAIL")
+	var resolveSlow Resolver
+	slow := NewPromise(func(resolve Resolver, reject Rejector) error {
+		resolveSlow = resolve
 		return nil
 	})
 
+	// All fails fast, while AllWaitFail holds the rejection until the slow promise settles
+	_, err := All(Reject(fail), slow).Wait()
+	Assert(t, err == fail, "All() did not fail fast: %v", err)
+
+	prom := AllWaitFail(Reject(fail), slow)
+	<-time.After(10 * time.Millisecond)
+	Assert(t, prom.GetStatus() == "Pending", "AllWaitFail() rejected before every promise settled")
+
+	resolveSlow("done")
+	_, err = prom.Wait()
+	multi, ok := (err).(*MultiPromiseError)
+	Assert(t, ok && len(multi.Outcomes()) == 2, "AllWaitFail() did not reject with every outcome: %v", err)
+	Assert(t, multi.Outcomes()[1].Result == "done", "AllWaitFail() outcomes are missing the slow result: %v", err)
+
+	res, err := AllWaitFail(Resolve(1), Resolve(2)).Wait()
+	values, _ := (res).([]Unknown)
+	Assert(t, err == nil && len(values) == 2 && values[1] == 2, "AllWaitFail() did not resolve with every result: %v, %v", res, err)
 }
 
-func TestPromise_AllSettled(t *testing.T) {
+func TestNewLazyPromiseError(t *testing.T) {
+	var calls atomic.Int64
+	err := NewLazyPromiseError(func() string {
+		calls.Add(1)
+		return fmt.Sprintf("item %d failed", 42)
+	})
 
-	promises := []Promise{
+	_, reason := Reject(err).Wait()
+	Assert(t, reason == err && calls.Load() == 0, "The message was formatted before Error() was called")
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(300 * time.Millisecond)
-				resolve(1)
-			}()
-			return nil
-		}),
+	Assert(t, err.Error() == "Promise error: item 42 failed", "Unexpected message: %s", err.Error())
+	Assert(t, err.Error() == "Promise error: item 42 failed", "Unexpected message: %s", err.Error())
+	Assert(t, calls.Load() == 1, "Expected the message to be formatted once, found %d", calls.Load())
+}
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(200 * time.Millisecond)
-				resolve(2)
-			}()
-			return nil
-		}),
+func TestPanicError(t *testing.T) {
+	fail := errors.New("FAIL")
+	var panicked *PanicError
 
-		NewPromise(func(resolve Resolver, reject Rejector) error {
-			go func() {
-				<-time.After(100 * time.Millisecond)
-				reject(errors.New("FOILED!"))
-			}()
-			return nil
-		}),
-	}
+	_, err := Resolve(1).Then(func(Unknown) Unknown {
+		panic("in resolver")
+	}, nil).Wait()
+	Assert(t, errors.As(err, &panicked) && panicked.Value == "in resolver", "A resolver panic produced: %v", err)
+	Assert(t, len(panicked.Stack) > 0, "PanicError did not capture the stack")
 
-	AllSettled(promises...).Then(func(u Unknown) Unknown {
-		result, ok := (u).([]*PromiseOutcome)
+	_, err = Reject(fail).Then(nil, func(error) Unknown {
+		panic(fail)
+	}).Wait()
+	Assert(t, errors.As(err, &panicked) && errors.Is(err, fail), "A rejector panic produced: %v", err)
 
-		if !(ok && (result != nil)) {
-			t.Errorf("Could not coerce promise outcomes for AllSettled()")
-		}
+	_, err = NewPromise(func(Resolver, Rejector) error {
+		panic("in handler")
+	}).Wait()
+	Assert(t, errors.As(err, &panicked) && err.Error() == "Promise error: panic: in handler", "A handler panic produced: %v", err)
 
-		Assert(t, len(promises) == len(result), "Results did not match promise input for AllSettled()")
+	_, err = Reject(fail).Wait()
+	Assert(t, !errors.As(err, &panicked), "A plain rejection was reported as a panic")
+}
 
-		Assert(t, result[0].Reason == nil, "First promise yielded unexpected error: %s", result[0].Reason)
-		Assert(t, result[1].Reason == nil, "Second promise yielded unexpected error: %s", result[1].Reason)
-		Assert(t, result[2].Reason != nil, "Third promise should have failed, but did not: %v", result[2].Result)
+func TestPromise_Quorum(t *testing.T) {
+	fake := useFakeClock(t)
+	never := NewPromise(func(Resolver, Rejector) error { return nil })
+
+	prom := Quorum(3,
+		ResolveAfter(30*time.Millisecond, "c"),
+		RejectAfter(5*time.Millisecond, errors.New("replica down")),
+		ResolveAfter(10*time.Millisecond, "a"),
+		never,
+		ResolveAfter(20*time.Millisecond, "b"),
+	)
+	fake.Advance(20 * time.Millisecond)
+	Assert(t, prom.GetStatus() == "Pending", "Quorum() settled with 2 of 3 fulfilled: %v", prom.GetStatus())
+	fake.Advance(10 * time.Millisecond)
+	res, err := prom.Wait()
+	values := (res).([]Unknown)
+	Assert(t, err == nil && len(values) == 3, "Quorum() did not resolve: %v, %v", res, err)
+	Assert(t, values[0] == "a" && values[1] == "b" && values[2] == "c", "Quorum() values are not in completion order: %v", values)
+
+	prom = Quorum(3,
+		RejectAfter(10*time.Millisecond, errors.New("first")),
+		ResolveAfter(5*time.Millisecond, true),
+		never,
+		RejectAfter(20*time.Millisecond, errors.New("second")),
+	)
+	fake.Advance(20 * time.Millisecond)
+	Assert(t, prom.GetStatus() == "Rejected", "Quorum() did not fail fast once unreachable: %v", prom.GetStatus())
+	_, err = prom.Wait()
+	multi, ok := (err).(*MultiPromiseError)
+	Assert(t, ok && len(multi.Errors()) == 2, "Quorum() rejected with an unexpected error: %v", err)
+
+	_, err = Quorum(2, Resolve(1)).Wait()
+	Assert(t, err != nil, "Quorum() accepted a quorum larger than its inputs")
+}
 
-		Assert(t, result[0].Result == 1, "First result did not match expected value: %v", result[0].Result)
-		Assert(t, result[1].Result == 2, "Second result did not match expected value: %v", result[1].Result)
+func TestPromise_AllIndexed(t *testing.T) {
+	fail := errors.New("FAIL")
+	_, err := AllIndexed(
+		ResolveAfter(5*time.Millisecond, "a"),
+		ResolveAfter(20*time.Millisecond, "b"),
+		RejectAfter(10*time.Millisecond, fail),
+	).Wait()
+	var indexed *IndexedError
+	Assert(t, errors.As(err, &indexed) && indexed.Index == 2, "AllIndexed() did not identify the failing promise: %v", err)
+	Assert(t, errors.Is(err, fail) && err.Error() == "promise 2: FAIL", "AllIndexed() did not wrap the error: %v", err)
+
+	res, err := AllIndexed(Resolve(1), Resolve(2)).Wait()
+	values := (res).([]Unknown)
+	Assert(t, err == nil && values[0] == 1 && values[1] == 2, "AllIndexed() did not resolve like All: %v, %v", res, err)
+
+	_, err = AllIndexed(Resolve(1), nil).Wait()
+	Assert(t, errors.As(err, &indexed) && indexed.Index == 1 && errors.Is(err, ErrNilPromise), "AllIndexed() mishandled a nil promise: %v", err)
+}
+
+func TestResolveRaw(t *testing.T) {
+	inner := Resolve("inner")
+
+	res, err := ResolveRaw(inner).Wait()
+	Assert(t, err == nil && res == inner, "ResolveRaw() did not resolve with the promise itself: %v, %v", res, err)
+	res, _ = Resolve(inner).Wait()
+	Assert(t, res == "inner", "Resolve() did not adopt the promise: %v", res)
+
+	var received Unknown
+	res, err = Resolve(1).ThenRaw(func(Unknown) Unknown {
+		return inner
+	}).ThenRaw(func(u Unknown) Unknown {
+		received = u
+		return u
+	}).Wait()
+	Assert(t, err == nil && received == inner && res == inner, "ThenRaw() adopted the returned promise: %v, %v", received, err)
+
+	fail := errors.New("FAIL")
+	res, err = Resolve(1).ThenRaw(func(Unknown) Unknown { return fail }).Wait()
+	Assert(t, err == nil && res == fail, "ThenRaw() rejected with a returned error: %v, %v", res, err)
+
+	_, err = Reject(fail).ThenRaw(func(u Unknown) Unknown { return u }).Wait()
+	Assert(t, err == fail, "ThenRaw() did not pass through a rejection: %v", err)
+
+	var panicked *PanicError
+	_, err = Resolve(1).ThenRaw(func(Unknown) Unknown { panic("raw") }).Wait()
+	Assert(t, errors.As(err, &panicked), "ThenRaw() did not reject on panic: %v", err)
+}
 
+// nestedThenable delivers another nestedThenable, depth times, before a value, each on the calling goroutine.
+type nestedThenable struct {
+	depth int
+	stack *int
+}
+
+func (n nestedThenable) Then(onsuccess Resolver, onfail Rejector) Promise {
+	if n.depth > 0 {
+		onsuccess(nestedThenable{depth: n.depth - 1, stack: n.stack})
+	} else {
+		*n.stack = runtime.Callers(0, make([]uintptr, 100000))
+		onsuccess("bottom")
+	}
+	return nil
+}
+
+func TestPromise_deepAdoption(t *testing.T) {
+	var shallow, deep int
+	Resolve(nestedThenable{depth: 1, stack: &shallow}).Wait()
+	res, err := Resolve(nestedThenable{depth: 10000, stack: &deep}).Wait()
+	Assert(t, err == nil && res == "bottom", "Adopting a deep chain produced: %v, %v", res, err)
+	Assert(t, deep == shallow, "Adoption grew the stack with depth: %d frames against %d", deep, shallow)
+
+	var resolve Resolver
+	self := NewPromise(func(r Resolver, _ Rejector) error {
+		resolve = r
 		return nil
-	}, func(e error) Unknown {
-		Assert(t, true, "AllSettled() failed with an unexpected error: %s", e)
+	})
+	resolve(self)
+	_, err = self.Wait()
+	Assert(t, err == ErrSelfResolution, "Resolving a promise with itself produced: %v", err)
+
+	cancelable := NewCancelablePromise(func(r Resolver, _ Rejector) error {
+		resolve = r
+		return nil
+	}, nil)
+	resolve(cancelable)
+	_, err = Timeout(cancelable, time.Second).Wait()
+	Assert(t, err == ErrSelfResolution, "Resolving a cancelable promise with itself produced: %v", err)
+
+	progress := NewProgressPromise(func(r Resolver, _ Rejector, _ func(float64)) error {
+		resolve = r
 		return nil
 	})
+	resolve(progress)
+	_, err = Timeout(progress, time.Second).Wait()
+	Assert(t, err == ErrSelfResolution, "Resolving a progress promise with itself produced: %v", err)
+}
 
+func TestPromise_Func(t *testing.T) {
+	thunk := ResolveAfter(5*time.Millisecond, "value").Func()
+	res, err := thunk()
+	Assert(t, err == nil && res == "value", "Func() returned an unexpected outcome: %v, %v", res, err)
+	res, err = thunk()
+	Assert(t, err == nil && res == "value", "Func() returned a different outcome on a second call: %v, %v", res, err)
+
+	fail := errors.New("FAIL")
+	_, err = Reject(fail).Func()()
+	Assert(t, err == fail, "Func() did not return the rejection: %v", err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	never := NewPromise(func(Resolver, Rejector) error { return nil })
+	thunk = never.FuncContext(ctx)
+	cancel()
+	_, err = thunk()
+	Assert(t, err == context.Canceled && never.GetStatus() == "Pending", "FuncContext() did not give up with the context: %v", err)
+
+	canceled := false
+	cancelable := NewCancelablePromise(func(Resolver, Rejector) error { return nil }, func() { canceled = true })
+	_, err = cancelable.FuncContext(ctx)()
+	Assert(t, err == context.Canceled && canceled, "FuncContext() did not cancel a cancelable promise: %v", err)
 }
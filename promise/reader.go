@@ -0,0 +1,81 @@
+package promise
+
+import (
+	"io"
+)
+
+// A stream promise resolves with an io.ReadCloser (or a plain io.Reader), such as an HTTP response body.
+// The helpers below consume it and always close it once it has been read, whatever the outcome.
+
+const defaultStreamChunk = 32 * 1024
+
+// StreamToBytes produces a Promise that resolves with the full contents of the stream p resolves with.
+func StreamToBytes(p Promise) Promise {
+	return p.OnResolved(func(u Unknown) Unknown {
+		reader, err := streamReader(u)
+		if err != nil {
+			return err
+		}
+		defer closeStream(reader)
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		return data
+	})
+}
+
+// StreamToChan delivers the stream p resolves with as chunks of at most chunk bytes.
+// The channel is closed when the stream is exhausted, fails to read, or p rejects;
+// read errors are not reported, so use StreamToBytes where they matter.
+// A consumer that stops receiving leaves the reading goroutine blocked, and the stream open.
+func StreamToChan(p Promise, chunk int) <-chan []byte {
+	if chunk <= 0 {
+		chunk = defaultStreamChunk
+	}
+
+	out := make(chan []byte)
+	p.Then(func(u Unknown) Unknown {
+		reader, err := streamReader(u)
+		if err != nil {
+			close(out)
+			return err
+		}
+
+		go func() {
+			defer close(out)
+			defer closeStream(reader)
+
+			for {
+				buf := make([]byte, chunk)
+				n, err := reader.Read(buf)
+				if n > 0 {
+					out <- buf[:n]
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+		return nil
+	}, func(e error) Unknown {
+		close(out)
+		return e
+	})
+	return out
+}
+
+func streamReader(u Unknown) (io.Reader, error) {
+	reader, ok := (u).(io.Reader)
+	if !ok || reader == nil {
+		return nil, NewPromiseError("stream promise did not resolve with an io.Reader")
+	}
+	return reader, nil
+}
+
+func closeStream(reader io.Reader) {
+	if closer, ok := (reader).(io.Closer); ok {
+		closer.Close()
+	}
+}
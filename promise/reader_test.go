@@ -0,0 +1,53 @@
+package promise
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type trackedReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *trackedReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestPromise_StreamToBytes(t *testing.T) {
+	body := &trackedReader{Reader: strings.NewReader("streamed body")}
+
+	res, err := StreamToBytes(Resolve(body)).Wait()
+	Assert(t, err == nil, "StreamToBytes() failed with an unexpected error: %s", err)
+	Assert(t, bytes.Equal((res).([]byte), []byte("streamed body")), "StreamToBytes() produced unexpected data: %v", res)
+	Assert(t, body.closed, "StreamToBytes() did not close the stream")
+
+	fail := errors.New("no body")
+	_, err = StreamToBytes(Reject(fail)).Wait()
+	Assert(t, err == fail, "StreamToBytes() did not pass the rejection through: %v", err)
+
+	_, err = StreamToBytes(Resolve(7)).Wait()
+	Assert(t, err != nil, "StreamToBytes() accepted a value that is not a stream")
+}
+
+func TestPromise_StreamToChan(t *testing.T) {
+	body := &trackedReader{Reader: strings.NewReader("abcdefg")}
+
+	chunks := []string{}
+	for chunk := range StreamToChan(Resolve(body), 3) {
+		chunks = append(chunks, string(chunk))
+	}
+
+	Assert(t, strings.Join(chunks, "|") == "abc|def|g", "StreamToChan() produced unexpected chunks: %v", chunks)
+	Assert(t, body.closed, "StreamToChan() did not close the stream")
+
+	count := 0
+	for range StreamToChan(Reject(errors.New("no body")), 3) {
+		count++
+	}
+	Assert(t, count == 0, "StreamToChan() delivered chunks for a rejected promise")
+}
@@ -0,0 +1,81 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// AllInto produces a Promise that, once all promises in m resolve, assigns each value to the field of the
+// struct dst points to whose `promise:"key"` tag, or else name, matches its key, and resolves with dst.
+// It rejects like All, or if a key has no matching field or a value doesn't fit its field; dst is
+// only written once every value has been checked.
+func AllInto(dst interface{}, m map[string]Promise) Promise {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return Reject(NewPromiseError(fmt.Sprintf("AllInto requires a non-nil pointer to a struct, found %T", dst)))
+	}
+
+	fields := promiseFields(target.Elem().Type())
+	keys := make([]string, 0, len(m))
+	proms := make([]Promise, 0, len(m))
+	for key, prom := range m {
+		if _, ok := fields[key]; !ok {
+			return Reject(NewPromiseError(fmt.Sprintf("AllInto found no field for key %q in %T", key, dst)))
+		}
+		keys = append(keys, key)
+		proms = append(proms, prom)
+	}
+
+	return All(proms...).OnResolved(func(u Unknown) Unknown {
+		values := (u).([]Unknown)
+		assigned := make([]reflect.Value, len(values))
+
+		for i, key := range keys {
+			field := target.Elem().Field(fields[key])
+			value, err := fieldValue(field.Type(), values[i])
+			if err != nil {
+				return NewPromiseError(fmt.Sprintf("AllInto key %q: %s", key, err))
+			}
+			assigned[i] = value
+		}
+
+		for i, key := range keys {
+			target.Elem().Field(fields[key]).Set(assigned[i])
+		}
+		return dst
+	})
+}
+
+// promiseFields maps the keys of the settable fields of a struct type to their index.
+func promiseFields(t reflect.Type) map[string]int {
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		if key, ok := field.Tag.Lookup("promise"); ok {
+			fields[key] = i
+		} else {
+			fields[field.Name] = i
+		}
+	}
+	return fields
+}
+
+// fieldValue checks that val can be stored in a field of type t.
+func fieldValue(t reflect.Type, val Unknown) (reflect.Value, error) {
+	if val == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot assign nil to %s", t)
+	}
+
+	value := reflect.ValueOf(val)
+	if !value.Type().AssignableTo(t) {
+		return reflect.Value{}, fmt.Errorf("cannot assign %s to %s", value.Type(), t)
+	}
+	return value, nil
+}
@@ -0,0 +1,51 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+)
+
+type intoConfig struct {
+	Host   string `promise:"host"`
+	Port   int
+	Tags   []string
+	secret string
+}
+
+func TestPromise_AllInto(t *testing.T) {
+	config := &intoConfig{}
+	res, err := AllInto(config, map[string]Promise{
+		"host": Resolve("localhost"),
+		"Port": Resolve(8080),
+		"Tags": Resolve(nil),
+	}).Wait()
+
+	Assert(t, err == nil, "AllInto() failed with an unexpected error: %s", err)
+	Assert(t, res == config, "AllInto() did not resolve with the destination: %v", res)
+	Assert(t, config.Host == "localhost" && config.Port == 8080 && config.Tags == nil,
+		"AllInto() assigned unexpected values: %+v", config)
+
+	config = &intoConfig{Host: "unchanged"}
+	_, err = AllInto(config, map[string]Promise{
+		"host": Resolve("localhost"),
+		"Port": Resolve("8080"),
+	}).Wait()
+	Assert(t, err != nil, "AllInto() accepted a mismatching type")
+	Assert(t, config.Host == "unchanged", "AllInto() wrote the destination despite a mismatch: %+v", config)
+
+	fail := errors.New("FAIL")
+	_, err = AllInto(&intoConfig{}, map[string]Promise{"host": Reject(fail)}).Wait()
+	Assert(t, err == fail, "AllInto() did not reject with the input error: %v", err)
+
+	_, err = AllInto(&intoConfig{}, map[string]Promise{"Host": Resolve("tagged fields match by tag")}).Wait()
+	Assert(t, err != nil, "AllInto() matched a tagged field by name")
+
+	_, err = AllInto(&intoConfig{}, map[string]Promise{"secret": Resolve("unexported")}).Wait()
+	Assert(t, err != nil, "AllInto() matched an unexported field")
+
+	_, err = AllInto(intoConfig{}, map[string]Promise{}).Wait()
+	Assert(t, err != nil, "AllInto() accepted a struct value")
+
+	_, err = AllInto((*intoConfig)(nil), map[string]Promise{}).Wait()
+	Assert(t, err != nil, "AllInto() accepted a nil pointer")
+}
@@ -0,0 +1,116 @@
+package promise
+
+import (
+	"context"
+	"sync"
+)
+
+// Registry tracks the promises created through it until they settle, e.g. to drain in-flight work before exit.
+type Registry struct {
+	mutex    sync.Mutex
+	pending  map[Promise]Rejector
+	idle     chan struct{} // closed whenever nothing is pending
+	shutdown error         // set by Shutdown
+}
+
+// ErrShuttingDown is the reason Shutdown rejects with when given no error of its own.
+var ErrShuttingDown = NewPromiseError("registry shutting down")
+
+func NewRegistry() *Registry {
+	idle := make(chan struct{})
+	close(idle)
+	return &Registry{
+		pending: make(map[Promise]Rejector),
+		idle:    idle,
+	}
+}
+
+// New creates a promise like NewPromise, and tracks it until it settles. After Shutdown, it does not call
+// handler and produces a promise rejected with the shutdown error instead.
+func (r *Registry) New(handler PromiseHandler) Promise {
+	r.mutex.Lock()
+	err := r.shutdown
+	r.mutex.Unlock()
+	if err != nil {
+		return Reject(err)
+	}
+
+	var rejector Rejector
+	prom := NewPromise(func(resolve Resolver, reject Rejector) error {
+		rejector = reject
+		return handler(resolve, reject)
+	})
+
+	r.mutex.Lock()
+	if len(r.pending) == 0 {
+		r.idle = make(chan struct{})
+	}
+	r.pending[prom] = rejector
+	err = r.shutdown
+	r.mutex.Unlock()
+
+	if err != nil {
+		rejector(err) // shut down while handler ran
+	}
+
+	release := func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.pending, prom)
+		if len(r.pending) == 0 {
+			close(r.idle)
+		}
+	}
+
+	prom.Then(func(Unknown) Unknown {
+		release()
+		return nil
+	}, func(error) Unknown {
+		release()
+		return nil
+	})
+	return prom
+}
+
+// Pending reports the number of tracked promises that have not settled yet.
+func (r *Registry) Pending() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.pending)
+}
+
+// WaitAll blocks until every tracked promise has settled, or until ctx is done.
+func (r *Registry) WaitAll(ctx context.Context) error {
+	r.mutex.Lock()
+	idle := r.idle
+	r.mutex.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown rejects every tracked promise that is still pending with err, or ErrShuttingDown if err is nil,
+// and makes New reject from then on. A promise that settles before Shutdown reaches it keeps its outcome.
+func (r *Registry) Shutdown(err error) {
+	if err == nil {
+		err = ErrShuttingDown
+	}
+
+	r.mutex.Lock()
+	if r.shutdown == nil {
+		r.shutdown = err
+	}
+	rejectors := make([]Rejector, 0, len(r.pending))
+	for _, reject := range r.pending {
+		rejectors = append(rejectors, reject)
+	}
+	r.mutex.Unlock()
+
+	for _, reject := range rejectors {
+		reject(err)
+	}
+}
@@ -0,0 +1,73 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	Assert(t, registry.Pending() == 0, "New registry has pending promises: %d", registry.Pending())
+	Assert(t, registry.WaitAll(context.Background()) == nil, "WaitAll() on an empty registry failed")
+
+	resolvers := []Resolver{}
+	rejectors := []Rejector{}
+	for i := 0; i < 3; i++ {
+		registry.New(func(resolve Resolver, reject Rejector) error {
+			resolvers = append(resolvers, resolve)
+			rejectors = append(rejectors, reject)
+			return nil
+		})
+	}
+	Assert(t, registry.Pending() == 3, "Registry reports %d pending promises, expected 3", registry.Pending())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := registry.WaitAll(ctx)
+	Assert(t, err == context.DeadlineExceeded, "WaitAll() did not honour the context: %v", err)
+
+	resolvers[0](1)
+	rejectors[1](errors.New("FAIL"))
+	resolvers[2](3)
+
+	err = registry.WaitAll(context.Background())
+	Assert(t, err == nil, "WaitAll() failed with an unexpected error: %v", err)
+	Assert(t, registry.Pending() == 0, "Registry still reports %d pending promises", registry.Pending())
+}
+
+func TestRegistry_Shutdown(t *testing.T) {
+	registry := NewRegistry()
+	resolvers := []Resolver{}
+	proms := []Promise{}
+	for i := 0; i < 3; i++ {
+		proms = append(proms, registry.New(func(resolve Resolver, reject Rejector) error {
+			resolvers = append(resolvers, resolve)
+			return nil
+		}))
+	}
+	resolvers[0]("finished")
+
+	registry.Shutdown(nil)
+	res, err := proms[0].Wait()
+	Assert(t, err == nil && res == "finished", "Shutdown() changed a settled promise: %v, %v", res, err)
+	for _, prom := range proms[1:] {
+		_, err = prom.Wait()
+		Assert(t, err == ErrShuttingDown, "Shutdown() did not reject a pending promise: %v", err)
+	}
+
+	resolvers[1]("late")
+	_, err = proms[1].Wait()
+	Assert(t, err == ErrShuttingDown, "A resolution after Shutdown() overrode the rejection: %v", err)
+
+	err = registry.WaitAll(context.Background())
+	Assert(t, err == nil && registry.Pending() == 0, "Registry still reports %d pending promises", registry.Pending())
+
+	called := false
+	_, err = registry.New(func(Resolver, Rejector) error {
+		called = true
+		return nil
+	}).Wait()
+	Assert(t, err == ErrShuttingDown && !called, "New() after Shutdown() ran its handler: %v", err)
+}
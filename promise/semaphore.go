@@ -0,0 +1,67 @@
+package promise
+
+import "sync"
+
+// Semaphore limits how many holders run at once, across any code that shares it, with Acquire producing a
+// Promise of a slot rather than blocking.
+type Semaphore struct {
+	mutex   sync.Mutex
+	free    int
+	size    int
+	waiters []Resolver
+}
+
+func NewSemaphore(size int) *Semaphore {
+	return &Semaphore{free: size, size: size}
+}
+
+// Acquire produces a Promise that resolves once the caller holds a slot, which it must give back with
+// Release. Waiters are granted slots in the order they called Acquire.
+func (sem *Semaphore) Acquire() Promise {
+	sem.mutex.Lock()
+	defer sem.mutex.Unlock()
+	if sem.free > 0 {
+		sem.free -= 1
+		return Resolve(true)
+	}
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		sem.waiters = append(sem.waiters, resolve)
+		return nil
+	})
+}
+
+// Release gives back a slot, handing it to the longest waiting Acquire if there is one. It panics if no slot
+// is held.
+func (sem *Semaphore) Release() {
+	sem.mutex.Lock()
+	if len(sem.waiters) > 0 {
+		next := sem.waiters[0]
+		sem.waiters = sem.waiters[1:]
+		sem.mutex.Unlock()
+		next(true)
+		return
+	}
+	defer sem.mutex.Unlock()
+	if sem.free == sem.size {
+		panic(NewPromiseError("Semaphore released without Acquire"))
+	}
+	sem.free += 1
+}
+
+// Guard produces a Promise that settles like the one returned by factory, which runs only once a slot of sem
+// is held. The slot is released when that promise settles.
+func Guard(sem *Semaphore, factory func() Promise) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		sem.Acquire().Then(func(Unknown) Unknown {
+			return factory()
+		}, nil).subscribe(func(status uint, val Unknown, err error) {
+			sem.Release()
+			if status == PromiseResolved {
+				resolve(val)
+			} else {
+				reject(err)
+			}
+		})
+		return nil
+	})
+}
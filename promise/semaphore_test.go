@@ -0,0 +1,59 @@
+package promise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGuard(t *testing.T) {
+	sem := NewSemaphore(3)
+	var running, peak atomic.Int64
+
+	work := func() Promise {
+		n := running.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		return ResolveAfter(5*time.Millisecond, true).Then(func(u Unknown) Unknown {
+			running.Add(-1)
+			return u
+		}, nil)
+	}
+
+	proms := make([]Promise, 20)
+	for i := range proms {
+		proms[i] = Guard(sem, work)
+	}
+	_, err := All(proms...).Wait()
+	Assert(t, err == nil, "Guard() rejected: %v", err)
+	Assert(t, peak.Load() == 3, "Expected at most 3 concurrent holders, found %d", peak.Load())
+
+	fail := errors.New("FAIL")
+	_, err = Guard(sem, func() Promise { return Reject(fail) }).Wait()
+	Assert(t, err == fail, "Guard() did not pass through the rejection: %v", err)
+
+	_, err = All(sem.Acquire(), sem.Acquire(), sem.Acquire()).Wait()
+	Assert(t, err == nil, "Slots were not released after settling: %v", err)
+}
+
+func TestSemaphore_release(t *testing.T) {
+	sem := NewSemaphore(1)
+	sem.Acquire().Wait()
+
+	next := sem.Acquire()
+	Assert(t, next.GetStatus() == "Pending", "Acquire() resolved with no free slot")
+	sem.Release()
+	_, err := next.Wait()
+	Assert(t, err == nil, "Release() did not hand the slot to the waiter")
+
+	sem.Release()
+	defer func() {
+		Assert(t, recover() != nil, "Release() without Acquire did not panic")
+	}()
+	sem.Release()
+}
@@ -0,0 +1,286 @@
+package promise
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the source of time for promises: timestamps, and the timers behind retries and timeouts.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call scheduled by a Clock.
+type Timer interface {
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+type clockHolder struct {
+	Clock
+}
+
+var clock = func() *atomic.Value {
+	value := &atomic.Value{}
+	value.Store(clockHolder{realClock{}})
+	return value
+}()
+
+// SetClock replaces the clock used by the package, e.g. with a fake one in tests; nil restores the real clock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = realClock{}
+	}
+	clock.Store(clockHolder{c})
+}
+
+func now() time.Time {
+	return clock.Load().(clockHolder).Now()
+}
+
+func afterFunc(d time.Duration, f func()) Timer {
+	return clock.Load().(clockHolder).AfterFunc(d, f)
+}
+
+// ErrDeadlineExceeded is wrapped by rejections caused by running out of time.
+var ErrDeadlineExceeded = NewPromiseError("deadline exceeded")
+
+// Backoff computes the delay before a retry, given the number of attempts made so far.
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff waits d before every retry.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff doubles the delay after every attempt, starting at base and capped at max.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			return max
+		}
+		return delay
+	}
+}
+
+// RetryUntil calls factory until the promise it produces fulfills, waiting according to backoff between
+// attempts. Once the next attempt would start at or after deadline, it rejects with the last error,
+// wrapped together with ErrDeadlineExceeded. A factory that panics rejects it with a *PanicError.
+func RetryUntil(deadline time.Time, backoff Backoff, factory func() Promise) Promise {
+	if backoff == nil {
+		backoff = ConstantBackoff(0)
+	}
+
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		var attempt func(n int)
+		attempt = func(n int) {
+			defer func() { // retries run on the timer's goroutine
+				if r := recover(); r != nil {
+					reject(recoveredError(r))
+				}
+			}()
+
+			factory().Then(resolve, func(err error) Unknown {
+				delay := backoff(n)
+				if !now().Add(delay).Before(deadline) {
+					counters.timedOut.Add(1)
+					return reject(fmt.Errorf("%w: %w", ErrDeadlineExceeded, err))
+				}
+				afterFunc(delay, func() {
+					attempt(n + 1)
+				})
+				return nil
+			})
+		}
+
+		attempt(1)
+		return nil
+	})
+}
+
+// Poll calls check now and then every interval until it reports ready, e.g. to wait for a port, a file or a
+// database row. It resolves with the value of the first check that returns true, and rejects with the first
+// error check returns, or with a PanicError if check panics. Polling stops once the promise settles.
+func Poll(interval time.Duration, check func() (Unknown, bool, error)) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		var poll func()
+		poll = func() {
+			defer func() {
+				if r := recover(); r != nil {
+					reject(recoveredError(r))
+				}
+			}()
+
+			val, ready, err := check()
+			switch {
+			case err != nil:
+				reject(err)
+			case ready:
+				resolve(val)
+			default:
+				afterFunc(interval, poll)
+			}
+		}
+
+		poll()
+		return nil
+	})
+}
+
+// RacePreferred settles like Race, except that primary is preferred over slightly faster others: when one of
+// others settles first, its outcome is held for grace, and primary wins if it settles within that window.
+// Only the first of others to settle is considered.
+func RacePreferred(grace time.Duration, primary Promise, others ...Promise) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		var mutex sync.Mutex
+		var timer Timer
+		held := false
+
+		stop := func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+
+		primary.Then(func(u Unknown) Unknown {
+			stop()
+			return resolve(u)
+		}, func(err error) Unknown {
+			stop()
+			return reject(err)
+		})
+
+		hold := func(settle func()) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if held {
+				return
+			}
+			held = true
+			timer = afterFunc(grace, settle)
+		}
+
+		for _, p := range others {
+			p.Then(func(u Unknown) Unknown {
+				hold(func() { resolve(u) })
+				return nil
+			}, func(err error) Unknown {
+				hold(func() { reject(err) })
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+// Timeout produces a Promise that settles like p, unless d passes first, in which case it rejects with
+// ErrDeadlineExceeded. p itself keeps running; only the derived promise gives up on it.
+func Timeout(p Promise, d time.Duration) Promise {
+	return timeout(traits{}, p, d, ErrDeadlineExceeded)
+}
+
+// Deadline is Timeout for a fluent chain, with an absolute time: the derived promise settles like p, unless t
+// passes first, in which case it rejects with ErrDeadlineExceeded.
+func (p *aPromise) Deadline(t time.Time) Promise {
+	return timeout(p.derive(), p, t.Sub(now()), ErrDeadlineExceeded)
+}
+
+// timeout settles like p, or rejects with expired once d passes.
+func timeout(inherit traits, p Promise, d time.Duration, expired error) Promise {
+	return newPromise(inherit, func(resolve Resolver, reject Rejector) error {
+		timer := afterFunc(d, func() {
+			counters.timedOut.Add(1)
+			reject(expired)
+		})
+		p.Then(func(u Unknown) Unknown {
+			timer.Stop()
+			return resolve(u)
+		}, func(err error) Unknown {
+			timer.Stop()
+			return reject(err)
+		})
+		return nil
+	})
+}
+
+// AllElementTimeout is All with a timeout of d applied to each input, so a single straggler cannot hold up
+// the batch forever. An element that times out rejects the batch with an *IndexedError identifying it, which
+// wraps ErrDeadlineExceeded; other rejections pass through as is, even an ErrDeadlineExceeded of the input's own.
+func AllElementTimeout(d time.Duration, proms ...Promise) Promise {
+	bounded := make([]Promise, len(proms))
+	for i, p := range proms {
+		if isNilPromise(p) {
+			continue // All reports it
+		}
+		bounded[i] = timeout(traits{}, p, d, &IndexedError{Index: i, Err: ErrDeadlineExceeded})
+	}
+	return All(bounded...)
+}
+
+// At produces a Promise that resolves with nil at the instant t, or immediately if t has already passed.
+// Race it against other work to do that work until t.
+func At(t time.Time) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		delay := t.Sub(now())
+		if delay <= 0 {
+			resolve(nil)
+			return nil
+		}
+		afterFunc(delay, func() {
+			resolve(nil)
+		})
+		return nil
+	})
+}
+
+// ResolveAfter produces a Promise that resolves with val once d has passed.
+func ResolveAfter(d time.Duration, val Unknown) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		afterFunc(d, func() {
+			resolve(val)
+		})
+		return nil
+	})
+}
+
+// RejectAfter produces a Promise that rejects with err once d has passed.
+func RejectAfter(d time.Duration, err error) Promise {
+	return NewPromise(func(resolve Resolver, reject Rejector) error {
+		afterFunc(d, func() {
+			reject(err)
+		})
+		return nil
+	})
+}
+
+// TimeoutWithSalvage is Timeout that keeps the late result: the channel delivers the outcome of p once it
+// settles, whether or not the promise timed out before that, and then closes. The channel is buffered, so
+// nothing blocks if it is never read, and no goroutine waits on p; if p never settles, neither does the channel.
+func TimeoutWithSalvage(p Promise, d time.Duration) (Promise, <-chan *PromiseOutcome) {
+	salvage := make(chan *PromiseOutcome, 1)
+	p.subscribe(func(uint, Unknown, error) {
+		salvage <- p.consumerOutcome()
+		close(salvage)
+	})
+	return Timeout(p, d), salvage
+}
@@ -0,0 +1,358 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when advanced, running the timers that fall due on the advancing goroutine.
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock   *fakeClock
+	when    time.Time
+	fn      func()
+	stopped bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	timer := &fakeTimer{clock: c, when: c.now.Add(d), fn: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	target := c.now.Add(d)
+	for {
+		var next *fakeTimer
+		index := -1
+		for i, timer := range c.timers {
+			if !timer.when.After(target) && (next == nil || timer.when.Before(next.when)) {
+				next, index = timer, i
+			}
+		}
+		if next == nil {
+			break
+		}
+		c.timers = append(c.timers[:index], c.timers[index+1:]...)
+		c.now = next.when
+		c.mutex.Unlock()
+		next.fn()
+		c.mutex.Lock()
+	}
+	c.now = target
+	c.mutex.Unlock()
+}
+
+// Pending reports the number of timers waiting to fire.
+func (c *fakeClock) Pending() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// useFakeClock installs a fake clock and an inline executor, so time-based promises settle deterministically.
+func useFakeClock(t *testing.T) *fakeClock {
+	fake := newFakeClock()
+	SetClock(fake)
+	SetExecutor(InlineExecutor)
+	t.Cleanup(func() {
+		SetClock(nil)
+		SetExecutor(nil)
+	})
+	return fake
+}
+
+func TestRetryUntil(t *testing.T) {
+	fake := useFakeClock(t)
+
+	attempts := 0
+	prom := RetryUntil(fake.Now().Add(time.Second), ConstantBackoff(10*time.Millisecond), func() Promise {
+		attempts += 1
+		if attempts < 3 {
+			return Reject(errors.New("not yet"))
+		}
+		return Resolve("reached")
+	})
+
+	Assert(t, attempts == 1, "RetryUntil() made %d attempts before any time passed", attempts)
+	fake.Advance(10 * time.Millisecond)
+	Assert(t, attempts == 2, "RetryUntil() made %d attempts after one backoff", attempts)
+	fake.Advance(10 * time.Millisecond)
+
+	res, err := prom.Wait()
+	Assert(t, err == nil && res == "reached", "RetryUntil() produced an unexpected outcome: %v, %v", res, err)
+	Assert(t, attempts == 3, "RetryUntil() made %d attempts, expected 3", attempts)
+}
+
+func TestRetryUntil_deadline(t *testing.T) {
+	fake := useFakeClock(t)
+
+	attempts := 0
+	last := errors.New("unreachable")
+	prom := RetryUntil(fake.Now().Add(25*time.Millisecond), ConstantBackoff(10*time.Millisecond), func() Promise {
+		attempts += 1
+		return Reject(last)
+	})
+
+	fake.Advance(20 * time.Millisecond)
+	Assert(t, prom.GetStatus() == "Rejected", "RetryUntil() status was not Rejected past the deadline, found %v", prom.GetStatus())
+	Assert(t, attempts == 3, "RetryUntil() made %d attempts, expected 3", attempts)
+	Assert(t, fake.Pending() == 0, "RetryUntil() left %d timers scheduled", fake.Pending())
+
+	_, err := prom.Wait()
+	Assert(t, errors.Is(err, ErrDeadlineExceeded), "RetryUntil() rejection does not wrap ErrDeadlineExceeded: %v", err)
+	Assert(t, errors.Is(err, last), "RetryUntil() rejection does not wrap the last error: %v", err)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	for attempt, expected := range []time.Duration{10, 20, 40, 50, 50} {
+		delay := backoff(attempt + 1)
+		Assert(t, delay == expected*time.Millisecond, "Backoff for attempt %d was %s, expected %dms", attempt+1, delay, expected)
+	}
+}
+
+func TestRacePreferred(t *testing.T) {
+	fake := useFakeClock(t)
+
+	latent := func() (Promise, Resolver) {
+		var resolve Resolver
+		prom := NewPromise(func(r Resolver, _ Rejector) error {
+			resolve = r
+			return nil
+		})
+		return prom, resolve
+	}
+
+	// the primary arrives within the grace window, so it wins over the faster backup
+	primary, resolvePrimary := latent()
+	backup, resolveBackup := latent()
+	prom := RacePreferred(10*time.Millisecond, primary, backup)
+
+	resolveBackup("backup")
+	fake.Advance(5 * time.Millisecond)
+	Assert(t, prom.GetStatus() == "Pending", "RacePreferred() settled inside the grace window")
+	resolvePrimary("primary")
+
+	res, _ := prom.Wait()
+	Assert(t, res == "primary", "RacePreferred() did not prefer the primary within grace: %v", res)
+	Assert(t, fake.Pending() == 0, "RacePreferred() left %d timers scheduled", fake.Pending())
+
+	// the primary misses the grace window, so the backup wins
+	primary, resolvePrimary = latent()
+	backup, resolveBackup = latent()
+	prom = RacePreferred(10*time.Millisecond, primary, backup)
+
+	resolveBackup("backup")
+	fake.Advance(10 * time.Millisecond)
+	resolvePrimary("primary")
+
+	res, _ = prom.Wait()
+	Assert(t, res == "backup", "RacePreferred() did not fall back after the grace window: %v", res)
+
+	// the primary settling first wins outright
+	primary, resolvePrimary = latent()
+	backup, _ = latent()
+	prom = RacePreferred(10*time.Millisecond, primary, backup)
+
+	resolvePrimary("primary")
+	res, _ = prom.Wait()
+	Assert(t, res == "primary", "RacePreferred() did not settle with a primary that settled first: %v", res)
+}
+
+func TestRetryUntil_panic(t *testing.T) {
+	fake := useFakeClock(t)
+
+	attempts := 0
+	prom := RetryUntil(fake.Now().Add(time.Second), ConstantBackoff(10*time.Millisecond), func() Promise {
+		attempts += 1
+		if attempts == 2 {
+			panic("retry boom")
+		}
+		return Reject(errors.New("not yet"))
+	})
+	fake.Advance(10 * time.Millisecond)
+
+	_, err := prom.Wait()
+	var panicked *PanicError
+	Assert(t, errors.As(err, &panicked) && attempts == 2, "RetryUntil() did not reject on a panicking retry: %v", err)
+	Assert(t, fake.Pending() == 0, "RetryUntil() kept retrying after a panic")
+}
+
+func TestPoll(t *testing.T) {
+	fake := useFakeClock(t)
+
+	checks := 0
+	res, err := Poll(time.Second, func() (Unknown, bool, error) {
+		checks += 1
+		return "ready", true, nil
+	}).Wait()
+	Assert(t, err == nil && res == "ready" && checks == 1, "Poll() did not resolve on an immediate check: %v, %v", res, err)
+	Assert(t, fake.Pending() == 0, "Poll() kept polling after it resolved")
+
+	checks = 0
+	prom := Poll(10*time.Millisecond, func() (Unknown, bool, error) {
+		checks += 1
+		return checks, checks == 4, nil
+	})
+	fake.Advance(20 * time.Millisecond)
+	Assert(t, prom.GetStatus() == "Pending" && checks == 3, "Poll() made %d checks in two intervals", checks)
+	fake.Advance(10 * time.Millisecond)
+	res, err = prom.Wait()
+	Assert(t, err == nil && res == 4, "Poll() did not resolve on the fourth check: %v, %v", res, err)
+	Assert(t, fake.Pending() == 0, "Poll() left %d timers scheduled", fake.Pending())
+
+	fail := errors.New("FAIL")
+	checks = 0
+	prom = Poll(10*time.Millisecond, func() (Unknown, bool, error) {
+		checks += 1
+		if checks == 2 {
+			return nil, false, fail
+		}
+		return nil, false, nil
+	})
+	fake.Advance(30 * time.Millisecond)
+	_, err = prom.Wait()
+	Assert(t, err == fail && checks == 2, "Poll() did not stop on the error: %v after %d checks", err, checks)
+	Assert(t, fake.Pending() == 0, "Poll() kept polling after it rejected")
+}
+
+func TestTimeout(t *testing.T) {
+	fake := useFakeClock(t)
+
+	never := NewPromise(func(Resolver, Rejector) error { return nil })
+	prom := Timeout(never, 10*time.Millisecond)
+	fake.Advance(10 * time.Millisecond)
+
+	_, err := prom.Wait()
+	Assert(t, err == ErrDeadlineExceeded, "Timeout() did not reject with ErrDeadlineExceeded: %v", err)
+
+	res, err := Timeout(Resolve("fast"), 10*time.Millisecond).Wait()
+	Assert(t, err == nil && res == "fast", "Timeout() did not settle with a fast promise: %v, %v", res, err)
+	Assert(t, fake.Pending() == 0, "Timeout() left %d timers scheduled", fake.Pending())
+}
+
+func TestPromise_Deadline(t *testing.T) {
+	fake := useFakeClock(t)
+	deadline := fake.Now().Add(20 * time.Millisecond)
+
+	late := ResolveAfter(30*time.Millisecond, 1).Then(func(u Unknown) Unknown {
+		return (u).(int) + 1
+	}, nil).Deadline(deadline)
+	fake.Advance(20 * time.Millisecond)
+	Assert(t, late.GetStatus() == "Rejected", "Deadline() did not reject at its time, found %v", late.GetStatus())
+	_, err := late.Wait()
+	Assert(t, err == ErrDeadlineExceeded, "Deadline() did not reject with ErrDeadlineExceeded: %v", err)
+
+	early := ResolveAfter(5*time.Millisecond, "early").Deadline(fake.Now().Add(time.Second))
+	fake.Advance(10 * time.Millisecond)
+	res, err := early.Wait()
+	Assert(t, err == nil && res == "early", "Deadline() did not settle with an early promise: %v, %v", res, err)
+	Assert(t, fake.Pending() == 0, "Deadline() left %d timers scheduled", fake.Pending())
+}
+
+func TestAllElementTimeout(t *testing.T) {
+	fake := useFakeClock(t)
+
+	never := NewPromise(func(Resolver, Rejector) error { return nil })
+	prom := AllElementTimeout(10*time.Millisecond, Resolve(1), never, Resolve(3))
+	fake.Advance(10 * time.Millisecond)
+
+	_, err := prom.Wait()
+	var indexed *IndexedError
+	Assert(t, errors.As(err, &indexed) && indexed.Index == 1, "AllElementTimeout() did not identify the straggler: %v", err)
+	Assert(t, errors.Is(err, ErrDeadlineExceeded), "AllElementTimeout() rejection does not wrap ErrDeadlineExceeded: %v", err)
+
+	_, err = AllElementTimeout(10*time.Millisecond, Resolve(1), Reject(ErrDeadlineExceeded)).Wait()
+	Assert(t, err == ErrDeadlineExceeded, "AllElementTimeout() reported an input's own deadline as a timeout: %v", err)
+
+	var typed *aPromise
+	_, err = AllElementTimeout(10*time.Millisecond, Resolve(1), typed).Wait()
+	Assert(t, errors.As(err, &indexed) && indexed.Index == 1 && errors.Is(err, ErrNilPromise), "AllElementTimeout() mishandled a nil promise: %v", err)
+}
+
+func TestAt_time(t *testing.T) {
+	fake := useFakeClock(t)
+
+	past := At(fake.Now().Add(-time.Second))
+	Assert(t, past.GetStatus() == "Resolved", "At() a past time did not resolve immediately, found %v", past.GetStatus())
+
+	future := At(fake.Now().Add(time.Second))
+	fake.Advance(999 * time.Millisecond)
+	Assert(t, future.GetStatus() == "Pending", "At() resolved before its time")
+	fake.Advance(time.Millisecond)
+
+	res, err := future.Wait()
+	Assert(t, err == nil && res == nil, "At() did not resolve with nil at its time: %v, %v", res, err)
+}
+
+func TestResolveAfter(t *testing.T) {
+	fake := useFakeClock(t)
+	fail := errors.New("FAIL")
+
+	resolved := ResolveAfter(10*time.Millisecond, "later")
+	rejected := RejectAfter(20*time.Millisecond, fail)
+
+	fake.Advance(10 * time.Millisecond)
+	Assert(t, resolved.GetStatus() == "Resolved", "ResolveAfter() did not resolve on time, found %v", resolved.GetStatus())
+	Assert(t, rejected.GetStatus() == "Pending", "RejectAfter() rejected early")
+
+	fake.Advance(10 * time.Millisecond)
+	res, _ := resolved.Wait()
+	_, err := rejected.Wait()
+	Assert(t, res == "later", "ResolveAfter() resolved with an unexpected value: %v", res)
+	Assert(t, err == fail, "RejectAfter() rejected with an unexpected error: %v", err)
+}
+
+func TestTimeoutWithSalvage(t *testing.T) {
+	fake := useFakeClock(t)
+
+	var resolve Resolver
+	slow := NewPromise(func(r Resolver, _ Rejector) error {
+		resolve = r
+		return nil
+	})
+	prom, salvage := TimeoutWithSalvage(slow, 10*time.Millisecond)
+
+	fake.Advance(10 * time.Millisecond)
+	_, err := prom.Wait()
+	Assert(t, err == ErrDeadlineExceeded, "TimeoutWithSalvage() did not time out: %v", err)
+
+	resolve("late")
+	outcome, ok := <-salvage
+	Assert(t, ok && outcome.Result == "late", "TimeoutWithSalvage() did not deliver the late result: %v", outcome)
+	_, ok = <-salvage
+	Assert(t, !ok, "TimeoutWithSalvage() did not close the salvage channel")
+}
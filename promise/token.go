@@ -0,0 +1,89 @@
+package promise
+
+import (
+	"sync"
+)
+
+// Token is a cancellation signal shared by a chain of promises.
+// Promises derived through Then inherit the token of their parent, so canceling it rejects
+// every pending promise of the chain with ErrCanceled, and skips their handlers.
+type Token interface {
+	Canceled() bool
+	Done() <-chan struct{}
+	onCancel(func()) (stop func())
+}
+
+type tokenSource struct {
+	mutex     sync.Mutex
+	done      chan struct{}
+	canceled  bool
+	callbacks map[int]func()
+	next      int
+}
+
+// NewToken creates a Token and the function that cancels it.
+func NewToken() (Token, func()) {
+	token := &tokenSource{
+		done:      make(chan struct{}),
+		callbacks: make(map[int]func()),
+	}
+	return token, token.cancel
+}
+
+// WithToken creates a promise like NewPromise, which rejects with ErrCanceled when token is canceled.
+// The handler doesn't run if token is already canceled.
+func WithToken(token Token, handler PromiseHandler) Promise {
+	if token.Canceled() {
+		handler = func(Resolver, Rejector) error {
+			return nil // the token's hook rejects with ErrCanceled
+		}
+	}
+	return newPromise(traits{token: token}, handler)
+}
+
+func (t *tokenSource) Canceled() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.canceled
+}
+
+func (t *tokenSource) Done() <-chan struct{} {
+	return t.done
+}
+
+func (t *tokenSource) cancel() {
+	t.mutex.Lock()
+	if t.canceled {
+		t.mutex.Unlock()
+		return
+	}
+	t.canceled = true
+	close(t.done)
+	callbacks := t.callbacks
+	t.callbacks = nil
+	t.mutex.Unlock()
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// onCancel registers fn to run when the token is canceled, or immediately if it already is.
+func (t *tokenSource) onCancel(fn func()) func() {
+	t.mutex.Lock()
+	if t.canceled {
+		t.mutex.Unlock()
+		fn()
+		return func() {}
+	}
+	id := t.next
+	t.next += 1
+	t.callbacks[id] = fn
+	t.mutex.Unlock()
+
+	return func() {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		delete(t.callbacks, id)
+	}
+}
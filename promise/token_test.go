@@ -0,0 +1,92 @@
+package promise
+
+import (
+	"testing"
+)
+
+func TestToken(t *testing.T) {
+	token, cancel := NewToken()
+	Assert(t, !token.Canceled(), "New token is already canceled")
+
+	var resolve Resolver
+	root := WithToken(token, func(r Resolver, _ Rejector) error {
+		resolve = r
+		return nil
+	})
+
+	hops := make(chan int, 2)
+	last := root.OnResolved(func(u Unknown) Unknown {
+		hops <- 1
+		return u
+	}).OnResolved(func(u Unknown) Unknown {
+		hops <- 2
+		return u
+	})
+
+	cancel()
+	cancel()
+	resolve("too late")
+
+	_, err := last.Wait()
+	Assert(t, err == ErrCanceled, "Last hop of a canceled chain has an unexpected rejection: %v", err)
+
+	_, err = root.Wait()
+	Assert(t, err == ErrCanceled, "Root of a canceled chain has an unexpected rejection: %v", err)
+	Assert(t, len(hops) == 0, "Handlers of a canceled chain ran %d times", len(hops))
+
+	select {
+	case <-token.Done():
+	default:
+		t.Errorf("Canceled token is not done")
+	}
+	Assert(t, token.Canceled(), "Token did not report cancellation")
+
+	ran := false
+	_, err = WithToken(token, func(r Resolver, _ Rejector) error {
+		ran = true
+		return nil
+	}).Wait()
+	Assert(t, err == ErrCanceled && !ran, "WithToken() ran a handler for a canceled token: %v", err)
+}
+
+func TestToken_settledBeforeCancel(t *testing.T) {
+	token, cancel := NewToken()
+
+	res, err := WithToken(token, func(resolve Resolver, _ Rejector) error {
+		resolve(2)
+		return nil
+	}).OnResolved(func(u Unknown) Unknown {
+		return (u).(int) * 2
+	}).Wait()
+	cancel()
+
+	Assert(t, err == nil && res == 4, "Chain settled before cancellation produced an unexpected outcome: %v, %v", res, err)
+}
+
+func TestToken_concurrentCancel(t *testing.T) {
+	for round := 0; round < 1000; round++ {
+		token, cancel := NewToken()
+		start := make(chan struct{})
+		go func() {
+			<-start
+			cancel()
+		}()
+		close(start)
+		prom := WithToken(token, func(Resolver, Rejector) error { return nil })
+
+		_, err := prom.Wait()
+		Assert(t, err == ErrCanceled, "A promise created during cancellation has an unexpected rejection: %v", err)
+	}
+}
+
+func TestToken_canceledUnderStrictMode(t *testing.T) {
+	StrictMode = true
+	defer func() {
+		StrictMode = false
+	}()
+
+	token, cancel := NewToken()
+	cancel()
+	_, err := WithToken(token, func(Resolver, Rejector) error { return nil }).Wait()
+	Assert(t, err == ErrCanceled, "WithToken() for a canceled token has an unexpected rejection: %v", err)
+}
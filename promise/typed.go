@@ -0,0 +1,199 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Pair holds the typed values of two joined promises.
+type Pair[A, B any] struct {
+	A A
+	B B
+}
+
+// Triple holds the typed values of three joined promises.
+type Triple[A, B, C any] struct {
+	A A
+	B B
+	C C
+}
+
+// Join2 produces a Promise that resolves with a Pair of the values of a and b, asserted to types A and B.
+// It rejects with the first error of either input, or with a type error if a value doesn't match.
+func Join2[A, B any](a, b Promise) Promise {
+	return All(a, b).OnResolved(func(u Unknown) Unknown {
+		values := (u).([]Unknown)
+		first, err := typed[A](values[0])
+		if err != nil {
+			return err
+		}
+		second, err := typed[B](values[1])
+		if err != nil {
+			return err
+		}
+		return Pair[A, B]{A: first, B: second}
+	})
+}
+
+// Join3 is like Join2, for three promises.
+func Join3[A, B, C any](a, b, c Promise) Promise {
+	return All(a, b, c).OnResolved(func(u Unknown) Unknown {
+		values := (u).([]Unknown)
+		first, err := typed[A](values[0])
+		if err != nil {
+			return err
+		}
+		second, err := typed[B](values[1])
+		if err != nil {
+			return err
+		}
+		third, err := typed[C](values[2])
+		if err != nil {
+			return err
+		}
+		return Triple[A, B, C]{A: first, B: second, C: third}
+	})
+}
+
+// ChannelOf is Channel with the result asserted to T, so consumers receive the concrete type. Exactly one of
+// the channels delivers, and both close once p settles; a result that isn't a T is delivered as a type error.
+// The Promise interface has no type parameter to attach this to as a method.
+func ChannelOf[T any](p Promise) (<-chan T, <-chan error) {
+	result := make(chan T, 1)
+	errout := make(chan error, 1)
+	p.subscribe(func(status uint, i Unknown, e error) {
+		if status == PromiseResolved {
+			if value, err := typed[T](i); err == nil {
+				result <- value
+			} else {
+				errout <- err
+			}
+		} else {
+			errout <- e
+		}
+		close(result)
+		close(errout)
+	})
+	return result, errout
+}
+
+// CatchAs is Catch for rejections whose error matches E by errors.As: handler receives the matched error,
+// and any other rejection passes through unchanged. It is a function, since methods cannot take type parameters.
+func CatchAs[E error](p Promise, handler func(E) Unknown) Promise {
+	return p.Catch(func(err error) Unknown {
+		var target E
+		if errors.As(err, &target) {
+			return handler(target)
+		}
+		return err
+	})
+}
+
+// As stores the result of p in *target, which must be a non-nil pointer, and derives a Promise that resolves
+// with the stored value. A value assignable to the target type is stored as is, and numbers convert between
+// numeric types when the conversion loses nothing. Any other value rejects the derived promise with a type
+// error, as do rejections of p, which pass through, and an invalid target. The assignment happens on the
+// callback's goroutine, so read *target only after the derived promise resolves. As with any handler, a
+// stored error value rejects the derived promise.
+func (p *aPromise) As(target interface{}) Promise {
+	dest := reflect.ValueOf(target)
+	if dest.Kind() != reflect.Ptr || dest.IsNil() {
+		return Reject(NewPromiseError(fmt.Sprintf("As requires a non-nil pointer, found %T", target)))
+	}
+	return p.OnResolved(func(u Unknown) Unknown {
+		value, err := coerce(u, dest.Elem().Type())
+		if err != nil {
+			return err
+		}
+		dest.Elem().Set(value)
+		return dest.Elem().Interface()
+	})
+}
+
+// coerce converts val to a value of type to, for As.
+func coerce(val Unknown, to reflect.Type) (reflect.Value, error) {
+	if val == nil {
+		switch to.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+			return reflect.Zero(to), nil
+		}
+		return reflect.Value{}, NewPromiseError(fmt.Sprintf("cannot assign nil to %s", to))
+	}
+
+	value := reflect.ValueOf(val)
+	if value.Type().AssignableTo(to) {
+		return value, nil
+	}
+	if isNumeric(value.Kind()) && isNumeric(to.Kind()) {
+		converted := value.Convert(to)
+		if converted.Convert(value.Type()).Interface() == val {
+			return converted, nil
+		}
+		return reflect.Value{}, NewPromiseError(fmt.Sprintf("cannot represent %v as %s", val, to))
+	}
+	return reflect.Value{}, NewPromiseError(fmt.Sprintf("cannot assign a value of type %T to %s", val, to))
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// typed asserts val to T, accepting nil for types that can hold it.
+func typed[T any](val Unknown) (T, error) {
+	var zero T
+	if val == nil {
+		switch reflect.TypeOf(&zero).Elem().Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+			return zero, nil
+		}
+	} else if result, ok := (val).(T); ok {
+		return result, nil
+	}
+	return zero, NewPromiseError(fmt.Sprintf("expected a value of type %s, found %T",
+		reflect.TypeOf(&zero).Elem(), val))
+}
+
+// AtString returns element i of a slice result, such as the one All resolves with, as a string.
+// It reports an error rather than panicking when result isn't a slice, i is out of range, or the element isn't a string.
+func AtString(result Unknown, i int) (string, error) {
+	return at[string](result, i)
+}
+
+// AtInt is like AtString, for int elements.
+func AtInt(result Unknown, i int) (int, error) {
+	return at[int](result, i)
+}
+
+// AtFloat64 is like AtString, for float64 elements.
+func AtFloat64(result Unknown, i int) (float64, error) {
+	return at[float64](result, i)
+}
+
+// AtBool is like AtString, for bool elements.
+func AtBool(result Unknown, i int) (bool, error) {
+	return at[bool](result, i)
+}
+
+func at[T any](result Unknown, i int) (T, error) {
+	var zero T
+	value := reflect.ValueOf(result)
+	if value.Kind() != reflect.Slice {
+		return zero, NewPromiseError(fmt.Sprintf("expected a slice result, found %T", result))
+	}
+	if i < 0 || i >= value.Len() {
+		return zero, NewPromiseError(fmt.Sprintf("index %d out of range for a result of length %d", i, value.Len()))
+	}
+
+	element, err := typed[T](value.Index(i).Interface())
+	if err != nil {
+		return zero, NewPromiseError(fmt.Sprintf("element %d: %s", i, err.(*PromiseError).description))
+	}
+	return element, nil
+}
@@ -0,0 +1,140 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPromise_Join2(t *testing.T) {
+	res, err := Join2[string, int](Resolve("answer"), Resolve(42)).Wait()
+	Assert(t, err == nil, "Join2() failed with an unexpected error: %s", err)
+
+	pair, ok := (res).(Pair[string, int])
+	Assert(t, ok, "Join2() did not resolve with a typed pair: %T", res)
+	Assert(t, pair.A == "answer" && pair.B == 42, "Join2() produced mismatching values: %v", pair)
+
+	fail := errors.New("FAIL")
+	_, err = Join2[string, int](Resolve("answer"), Reject(fail)).Wait()
+	Assert(t, err == fail, "Join2() did not reject with the input error: %v", err)
+
+	_, err = Join2[string, int](Resolve("answer"), Resolve("forty-two")).Wait()
+	Assert(t, err != nil, "Join2() accepted a value of the wrong type")
+}
+
+func TestPromise_Join3(t *testing.T) {
+	res, err := Join3[string, int, error](Resolve("answer"), Resolve(42), Resolve(nil)).Wait()
+	Assert(t, err == nil, "Join3() failed with an unexpected error: %s", err)
+
+	triple, ok := (res).(Triple[string, int, error])
+	Assert(t, ok, "Join3() did not resolve with a typed triple: %T", res)
+	Assert(t, triple.A == "answer" && triple.B == 42 && triple.C == nil, "Join3() produced mismatching values: %v", triple)
+
+	_, err = Join3[string, int, bool](Resolve("answer"), Resolve(nil), Resolve(true)).Wait()
+	Assert(t, err != nil, "Join3() accepted nil for a non-nillable type")
+}
+
+func TestAt(t *testing.T) {
+	res, _ := All(Resolve("Zero"), Resolve(1), Resolve(2.5), Resolve(true)).Wait()
+
+	text, err := AtString(res, 0)
+	Assert(t, err == nil && text == "Zero", "AtString() produced an unexpected value: %v, %v", text, err)
+
+	number, err := AtInt(res, 1)
+	Assert(t, err == nil && number == 1, "AtInt() produced an unexpected value: %v, %v", number, err)
+
+	float, err := AtFloat64(res, 2)
+	Assert(t, err == nil && float == 2.5, "AtFloat64() produced an unexpected value: %v, %v", float, err)
+
+	flag, err := AtBool(res, 3)
+	Assert(t, err == nil && flag, "AtBool() produced an unexpected value: %v, %v", flag, err)
+
+	_, err = AtInt(res, 0)
+	Assert(t, err != nil && err.Error() == "Promise error: element 0: expected a value of type int, found string",
+		"AtInt() produced an unexpected type error: %v", err)
+
+	_, err = AtString(res, 4)
+	Assert(t, err != nil && err.Error() == "Promise error: index 4 out of range for a result of length 4",
+		"AtString() produced an unexpected range error: %v", err)
+
+	_, err = AtString("not a slice", 0)
+	Assert(t, err != nil && err.Error() == "Promise error: expected a slice result, found string",
+		"AtString() produced an unexpected slice error: %v", err)
+}
+
+func TestChannelOf(t *testing.T) {
+	result, errout := ChannelOf[int](Resolve(42))
+	value, ok := <-result
+	Assert(t, ok && value == 42, "ChannelOf() did not deliver the typed result: %v, %v", value, ok)
+	_, ok = <-errout
+	Assert(t, !ok, "ChannelOf() delivered an error alongside the result")
+
+	fail := errors.New("FAIL")
+	result, errout = ChannelOf[int](Reject(fail))
+	err := <-errout
+	_, ok = <-result
+	Assert(t, err == fail && !ok, "ChannelOf() did not deliver the rejection alone: %v, %v", err, ok)
+
+	result, errout = ChannelOf[int](Resolve("forty-two"))
+	err = <-errout
+	_, ok = <-result
+	Assert(t, err != nil && !ok, "ChannelOf() accepted a result of the wrong type: %v", err)
+}
+
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d", e.code)
+}
+
+func TestCatchAs(t *testing.T) {
+	wrapped := fmt.Errorf("request failed: %w", &statusError{code: 404})
+	res, err := CatchAs(Reject(wrapped), func(e *statusError) Unknown {
+		return e.code
+	}).Wait()
+	Assert(t, err == nil && res == 404, "CatchAs() did not handle a matching error: %v, %v", res, err)
+
+	other := errors.New("FAIL")
+	res, err = CatchAs(Reject(other), func(e *statusError) Unknown {
+		return e.code
+	}).Wait()
+	Assert(t, err == other, "CatchAs() did not pass a non-matching error through: %v, %v", res, err)
+
+	res, err = CatchAs(Resolve("ok"), func(e *statusError) Unknown {
+		return e.code
+	}).Wait()
+	Assert(t, err == nil && res == "ok", "CatchAs() changed a resolution: %v, %v", res, err)
+}
+
+func TestPromise_As(t *testing.T) {
+	var count int
+	res, err := Resolve(42).As(&count).Wait()
+	Assert(t, err == nil && res == 42 && count == 42, "As() did not store a matching value: %v, %v, %d", res, err, count)
+
+	var stringer fmt.Stringer
+	res, err = Resolve(time.Second).As(&stringer).Wait()
+	Assert(t, err == nil && stringer == time.Second, "As() did not store an assignable value: %v, %v", stringer, err)
+
+	var ratio float64
+	res, err = Resolve(3).As(&ratio).Wait()
+	Assert(t, err == nil && res == 3.0 && ratio == 3.0, "As() did not convert a number: %v, %v", res, err)
+
+	var small int8
+	_, err = Resolve(300).As(&small).Wait()
+	Assert(t, err != nil && err.Error() == "Promise error: cannot represent 300 as int8", "As() did not reject a lossy conversion: %v", err)
+
+	var name string
+	_, err = Resolve(42).As(&name).Wait()
+	Assert(t, err != nil && err.Error() == "Promise error: cannot assign a value of type int to string", "As() did not reject a mismatch: %v", err)
+	Assert(t, name == "", "As() stored a mismatched value: %q", name)
+
+	fail := errors.New("FAIL")
+	_, err = Reject(fail).As(&count).Wait()
+	Assert(t, err == fail, "As() did not pass through a rejection: %v", err)
+
+	_, err = Resolve(42).As(count).Wait()
+	Assert(t, err != nil, "As() accepted a target that is not a pointer")
+}